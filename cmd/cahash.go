@@ -0,0 +1,54 @@
+// Copyright © 2018 Lee Briggs <lee@leebriggs.co.uk>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	c "github.com/apptio/kubeadm-bootstrap/pkg/certs"
+)
+
+// caHashCmd represents the ca-hash command
+var caHashCmd = &cobra.Command{
+	Use:   "ca-hash",
+	Short: "print the discovery token CA cert hash for the cluster CA",
+	Long: `Print the sha256 hash of the cluster CA's public key, in the format used by
+kubeadm join --discovery-token-ca-cert-hash`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		cert, err := c.LoadCertificate(caCert)
+		if err != nil {
+			log.Fatal("Error reading CA certificate: ", err)
+		}
+
+		fmt.Println(c.CACertHash(cert))
+
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(caHashCmd)
+}
@@ -0,0 +1,197 @@
+// Copyright © 2018 Lee Briggs <lee@leebriggs.co.uk>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	b "github.com/apptio/kubeadm-bootstrap/pkg/bootstrap"
+	c "github.com/apptio/kubeadm-bootstrap/pkg/certs"
+	v "github.com/apptio/kubeadm-bootstrap/pkg/validate"
+)
+
+var certDir string
+var keyType string
+var caValidity time.Duration
+var certValidity time.Duration
+var renew bool
+var checkCert string
+var expiryWarning time.Duration
+
+// certsCmd represents the certs command
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "generate the cluster CAs and the certificates kubeadm expects on a master",
+	Long: `Generate the cluster, front proxy and etcd CAs, the leaf certificates kubeadm
+expects on a master and the service account key pair, issued for the names and
+addresses of the config the other flags generate. Existing CAs and service
+account keys are reused, so a PKI generated once can be distributed to every
+master, and existing leaf certificates are kept unless --renew is given or
+their CA had to be generated`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		names := certNames(cmd)
+
+		results, err := c.Generate(names, c.Options{
+			Dir:          certDir,
+			KeyType:      keyType,
+			CAValidity:   caValidity,
+			CertValidity: certValidity,
+			Renew:        renew,
+		})
+		if err != nil {
+			log.Fatal("Error generating certificates: ", err)
+		}
+
+		for _, result := range results {
+			if result.Kept {
+				log.Info("Using existing ", result.Name)
+			} else {
+				log.Info("Generated ", result.Name)
+			}
+		}
+
+		caCertPath, _ := c.Paths(certDir, "ca")
+		ca, err := c.LoadCertificate(caCertPath)
+		if err != nil {
+			log.Fatal("Error reading CA certificate: ", err)
+		}
+		log.Info("Wrote certificates to ", certDir, ", nodes can join with discovery token CA cert hash: ", c.CACertHash(ca))
+
+	},
+}
+
+// certsCheckCmd represents the certs check command
+var certsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "check the API server certificate has the SANs of the config and hasn't expired",
+	Long: `Compare the DNS and IP SANs of an existing API server certificate with the ones
+the config the other flags generate needs, printing any that are missing or
+extra, and check when it expires. The exit code is non-zero if the SANs differ
+or the certificate has expired, so adding a master or discovery name, or
+changing the control plane endpoint, can be gated on reissuing it`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		path := checkCert
+		if path == "" {
+			path, _ = c.Paths(certDir, "apiserver")
+		}
+		cert, err := c.LoadCertificate(path)
+		if err != nil {
+			log.Fatal("Error reading certificate: ", err)
+		}
+
+		names, ips, err := certNames(cmd).APIServerSANs()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		failed := false
+		drift := c.CheckSANs(cert, names, ips)
+		for _, san := range drift.Missing {
+			fmt.Println("missing SAN", san)
+		}
+		for _, san := range drift.Extra {
+			fmt.Println("extra SAN", san)
+		}
+		if !drift.None() {
+			log.Error("Certificate SANs don't match the config: ", path)
+			failed = true
+		}
+
+		left := time.Until(cert.NotAfter)
+		expires := cert.NotAfter.UTC().Format(time.RFC3339)
+		switch {
+		case left <= 0:
+			log.Error("Certificate expired at ", expires, ": ", path)
+			failed = true
+		case left < expiryWarning:
+			log.Warn("Certificate expires at ", expires, ", in ", days(left), ": ", path)
+		default:
+			log.Info("Certificate expires at ", expires, ", in ", days(left))
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+		log.Info("Certificate matches the config: ", path)
+
+	},
+}
+
+// days describes a duration in whole days, or hours if it is less than one
+func days(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
+
+// certNames renders the master config and reads the names its certificates
+// are issued for, so they match the config's cert SANs
+func certNames(cmd *cobra.Command) c.Names {
+	resolved, err := b.Resolve(context.Background(), buildConfig(cmd, b.RoleMaster))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := b.Render(resolved)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	docs, err := v.Parse(out)
+	if err != nil {
+		log.Fatal("Error reading kubeadm config: ", err)
+	}
+
+	names, err := c.NamesFromConfig(docs)
+	if err != nil {
+		log.Fatal("Error reading kubeadm config: ", err)
+	}
+	return names
+}
+
+func init() {
+	RootCmd.AddCommand(certsCmd)
+	certsCmd.AddCommand(certsCheckCmd)
+
+	certsCmd.PersistentFlags().StringVarP(&certDir, "cert-dir", "", c.DefaultDir, "directory of the cluster PKI")
+	certsCmd.Flags().StringVarP(&keyType, "key-type", "", c.KeyRSA2048, "type of the generated keys ("+strings.Join(c.SupportedKeyTypes(), ", ")+")")
+	certsCmd.Flags().DurationVarP(&caValidity, "ca-validity", "", c.DefaultCAValidity, "how long generated CAs are valid")
+	certsCmd.Flags().DurationVarP(&certValidity, "cert-validity", "", c.DefaultCertValidity, "how long generated leaf certificates are valid, never longer than their CA")
+	certsCmd.Flags().BoolVarP(&renew, "renew", "", false, "regenerate existing leaf certificates, keeping the CAs and service account key")
+	certsCheckCmd.Flags().StringVarP(&checkCert, "cert", "", "", "API server certificate to check (default apiserver.crt in --cert-dir)")
+	certsCheckCmd.Flags().DurationVarP(&expiryWarning, "expiry-warning", "", 30*24*time.Hour, "warn if the certificate expires within this long")
+
+}
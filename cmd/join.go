@@ -0,0 +1,91 @@
+// Copyright © 2018 Lee Briggs <lee@leebriggs.co.uk>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	b "github.com/apptio/kubeadm-bootstrap/pkg/bootstrap"
+)
+
+// joinCmd represents the join command
+var joinCmd = &cobra.Command{
+	Use:   "join",
+	Short: "generate a kubeadm join config for additional masters and workers",
+	Long: `Generate the kubeadm config used to join additional control plane nodes or workers
+to a cluster bootstrapped with kubeadm-bootstrap. The bootstrap token must match
+the one used for the bootstrap master`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		writeConfig(cmd, renderJoin(cmd, role, token))
+
+	},
+}
+
+// joinRole checks the role of a joining node. Only control plane nodes and
+// workers join, the bootstrap master is generated by the root command
+func joinRole(role string) error {
+	if role != b.RoleControlPlane && role != b.RoleWorker {
+		return fmt.Errorf("invalid role %q, must be %s or %s", role, b.RoleControlPlane, b.RoleWorker)
+	}
+	return nil
+}
+
+// renderJoin renders and checks the join config for a role, which joins with
+// a bootstrap token
+func renderJoin(cmd *cobra.Command, role, token string) []byte {
+	if err := joinRole(role); err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := buildConfig(cmd, role)
+	cfg.Token = token
+
+	resolved, err := b.Resolve(context.Background(), cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := b.Render(resolved)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	validateConfig(out)
+	if !checkPolicy(out, os.Stderr) && enforce {
+		log.Fatal("Kubeadm config doesn't comply with ", benchmark, ", not writing it")
+	}
+	return out
+}
+
+func init() {
+	RootCmd.AddCommand(joinCmd)
+
+	joinCmd.Flags().StringVarP(&role, "role", "r", b.RoleWorker, "role of the joining node ("+b.RoleControlPlane+", "+b.RoleWorker+")")
+
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	b "github.com/apptio/kubeadm-bootstrap/pkg/bootstrap"
+)
+
+func TestJoinRole(t *testing.T) {
+	for _, role := range []string{b.RoleControlPlane, b.RoleWorker} {
+		if err := joinRole(role); err != nil {
+			t.Errorf("%s: %v", role, err)
+		}
+	}
+
+	// the bootstrap master doesn't join
+	for _, role := range []string{b.RoleMaster, "etcd", "Worker", ""} {
+		err := joinRole(role)
+		if want := `invalid role "` + role + `", must be control-plane or worker`; err == nil || err.Error() != want {
+			t.Errorf("%q: got error %v, want %s", role, err, want)
+		}
+	}
+}
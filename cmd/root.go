@@ -21,28 +21,33 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	"github.com/GeertJohan/go.rice"
-	jsonnet "github.com/google/go-jsonnet"
-
 	log "github.com/Sirupsen/logrus"
 
 	"io/ioutil"
 
-	"github.com/aws/aws-sdk-go/aws/ec2metadata"
-	"github.com/aws/aws-sdk-go/aws/session"
-	//"net"
 	"strings"
 
+	"path/filepath"
+
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	b "github.com/apptio/kubeadm-bootstrap/pkg/bootstrap"
+	f "github.com/apptio/kubeadm-bootstrap/pkg/facts"
+	"github.com/apptio/kubeadm-bootstrap/pkg/naming"
 	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+	p "github.com/apptio/kubeadm-bootstrap/pkg/policy"
 	t "github.com/apptio/kubeadm-bootstrap/pkg/token"
+	v "github.com/apptio/kubeadm-bootstrap/pkg/validate"
 )
 
 var cfgFile string
@@ -51,19 +56,59 @@ var clusterName string
 var datacenter string
 var domainName string
 var kubeadmFile string
-var cloudProvider string
-var hostname string
-var ipAddress string
-var detectedDomainName string
 var addressList string
-var addresses string
 var numberMasters int
 var svcIP string
-var dcName string
 var token string
-var generatedToken string
 var dryrun bool
 var quiet bool
+var apiVersion string
+var role string
+var caCert string
+var skipCAVerification bool
+var etcdMode string
+var etcdCount int
+var etcdEndpoints string
+var etcdCAFile string
+var etcdCertFile string
+var etcdKeyFile string
+var etcdDataDir string
+var etcdArgs []string
+var factProviders string
+var factsFile string
+var facterMappings []string
+var templateFile string
+var jpath []string
+var extStr []string
+var extCode []string
+var tlaStr []string
+var tlaCode []string
+var verbose bool
+var outputFormat string
+var benchmark string
+var policyDir string
+var enforce bool
+var kubernetesVersion string
+var components bool
+var podCIDR string
+var serviceCIDR string
+var dnsDomain string
+var cni string
+var masterSRV string
+var dnsTimeout time.Duration
+var advertiseInterface string
+var advertiseCIDR string
+var probeTarget string
+var tokenTTL time.Duration
+var tokenUsages string
+var tokenGroups string
+var tokenDescription string
+var masterNameTemplate string
+var etcdNameTemplate string
+var endpointNameTemplate string
+var discoveryNameTemplates []string
+var discoveryDomains string
+var verifyDiscoveryNames bool
 
 // Version string
 var Version string
@@ -76,170 +121,301 @@ var RootCmd = &cobra.Command{
 using jsonnet templates for the config file`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		if quiet {
-			// set logging to /dev/null
-			file, err := os.OpenFile("/dev/null", os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-			if err == nil {
-				log.SetOutput(file)
-			} else {
-				log.Fatal("Unable to open /dev/null.")
-			}
-		} else {
-			// set logging to stderr
-			log.SetOutput(os.Stderr)
-		}
+		setupLogging()
 
-		// read static assets
-		templateBox, err := rice.FindBox("../lib")
+		resolved, err := b.Resolve(context.Background(), buildConfig(cmd, b.RoleMaster))
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		tmpl, err := templateBox.String("kubeadm.libsonnet")
+		if resolved.CACertHash != "" {
+			log.Info("Nodes can join with discovery token CA cert hash: ", resolved.CACertHash)
+		}
+
+		out, err := b.Render(resolved)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		// create a jsonnet vm
-		vm := jsonnet.MakeVM()
-
-		// check for default required vars
-		if datacenter == "" {
-			log.Info("Auto detecting dc name")
-			out, err := exec.Command("facter", "-p", "datacenter").Output()
-			if err != nil {
-				log.Fatal("Error detecting datacenter from facter: ", err)
-			}
+		validateConfig(out)
+		if !checkPolicy(out, os.Stderr) && enforce {
+			log.Fatal("Kubeadm config doesn't comply with ", benchmark, ", not writing it")
+		}
+		writeConfig(cmd, out)
 
-			dcName = string(out)
-			dcName = strings.TrimSuffix(dcName, "\n")
+	},
+}
 
-			if dcName == "" {
-				log.Fatal("No datacenter provided")
-			}
-			log.Info("Datacenter name is: ", dcName)
+// setupLogging sends logs to stderr, or discards them with --quiet
+func setupLogging() {
+	if quiet {
+		// set logging to /dev/null
+		file, err := os.OpenFile("/dev/null", os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+		if err == nil {
+			log.SetOutput(file)
 		} else {
-			dcName = datacenter
-		}
-
-		if clusterName == "" {
-			log.Fatal("Please specify a cluster name")
+			log.Fatal("Unable to open /dev/null.")
 		}
+	} else {
+		// set logging to stderr
+		log.SetOutput(os.Stderr)
+	}
 
-		// determine if we're in AWS:
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+}
 
-		sess, err := session.NewSession()
+// splitList splits a comma separated flag, ignoring empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 
-		if err != nil {
-			log.Fatal("Error creating AWS session", err)
+// parseKeyValues parses repeated key=value flags into a map
+func parseKeyValues(flag string, values []string) map[string]string {
+	m := map[string]string{}
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			log.Fatal("Invalid ", flag, " ", value, ", must be key=value")
 		}
+		m[kv[0]] = kv[1]
+	}
+	return m
+}
 
-		// create an ec2metadata instance
-		svc := ec2metadata.New(sess)
+// buildConfig creates the generator config from the command line flags
+func buildConfig(cmd *cobra.Command, role string) b.Config {
+	facterFacts := map[string]string{}
+	for k, v := range f.DefaultFacterFacts {
+		facterFacts[k] = v
+	}
+	for k, v := range parseKeyValues("--facter-fact", facterMappings) {
+		facterFacts[k] = v
+	}
 
-		// check for AWs
-		if svc.Available() == true {
-			log.Info("Running in AWS")
-			cloudProvider = "aws"
+	chain, err := f.NewChain(factProviders, f.Options{
+		FactsFile:   factsFile,
+		FacterFacts: facterFacts,
+		Address: n.AddressSelector{
+			Interface: advertiseInterface,
+			CIDR:      advertiseCIDR,
+		},
+		ProbeTarget: probeTarget,
+	})
+	if err != nil {
+		log.Fatal("Error configuring fact providers: ", err)
+	}
 
-			awsHostname, err := svc.GetMetadata("local-hostname") // set hostname if in AWS
-			splitHostname := strings.Split(awsHostname, ".")
-			region, err := svc.Region()
+	file := readConfigFile()
+
+	return b.Config{
+		APIVersion:    apiVersion,
+		Role:          role,
+		Datacenter:    datacenter,
+		ClusterName:   clusterName,
+		DomainName:    domainName,
+		NodeName:      nodeName,
+		AddressList:   splitList(addressList),
+		NumberMasters: numberMasters,
+		MasterSRV:     masterSRV,
+		SvcIP:         svcIP,
+		Naming:        namingConventions(cmd, file),
+		Token:         token,
+		TokenOptions:  tokenOptions(),
+		CACert:        caCert,
+		RequireCACert: cmd.Flags().Changed("ca-cert"),
+
+		SkipCAVerification: skipCAVerification,
+
+		Etcd: b.EtcdConfig{
+			Mode:      etcdMode,
+			Count:     etcdCount,
+			Endpoints: splitList(etcdEndpoints),
+			CAFile:    etcdCAFile,
+			CertFile:  etcdCertFile,
+			KeyFile:   etcdKeyFile,
+			DataDir:   etcdDataDir,
+			ExtraArgs: parseKeyValues("--etcd-arg", etcdArgs),
+		},
+		Networking: b.NetworkConfig{
+			PodSubnet:     podCIDR,
+			ServiceSubnet: serviceCIDR,
+			DNSDomain:     dnsDomain,
+			CNI:           cni,
+		},
+		Template:          templateFile,
+		JPath:             jpath,
+		Vars:              templateVars(file),
+		OutputFormat:      outputFormat,
+		KubernetesVersion: kubernetesVersion,
+		Components:        components,
+		Facts:             chain,
+		LookupTimeout:     dnsTimeout,
+
+		VerifyDiscoveryNames: verifyDiscoveryNames,
+	}
+}
 
-			hostname = splitHostname[0] + "." + region + ".compute.internal"
-			if len(splitHostname) < 1 {
-				log.Warn("Cannot auto detect domainname")
-			} else {
-				detectedDomainName = splitHostname[1] + "." + splitHostname[2]
-			}
+// tokenOptions are the bootstrap token options from the command line flags
+func tokenOptions() *t.Options {
+	return &t.Options{
+		TTL:         tokenTTL,
+		Usages:      splitList(tokenUsages),
+		Groups:      splitList(tokenGroups),
+		Description: tokenDescription,
+	}
+}
 
-			if err != nil {
-				log.Fatal("Error getting metadata", err)
-			}
+// configFile is the part of the config file that is read directly. viper
+// lowercases nested keys, so template variable names wouldn't stay intact
+type configFile struct {
+	ExtVars map[string]string `yaml:"extVars"`
+	ExtCode map[string]string `yaml:"extCode"`
+	TLAVars map[string]string `yaml:"tlaVars"`
+	TLACode map[string]string `yaml:"tlaCode"`
+	Naming  struct {
+		Master    string   `yaml:"master"`
+		Etcd      string   `yaml:"etcd"`
+		Endpoint  string   `yaml:"endpoint"`
+		Discovery []string `yaml:"discovery"`
+		// an empty list means there are no discovery domains
+		DiscoveryDomains *[]string `yaml:"discoveryDomains"`
+	} `yaml:"naming"`
+}
 
-		} else {
-			log.Info("Not running in AWS")
-			cloudProvider = ""
-			hostname, err = os.Hostname()
-			if err != nil {
-				log.Fatal("Cannot detect hostname", err)
+// readConfigFile reads the config file directly, if it is YAML or JSON
+func readConfigFile() configFile {
+	var file configFile
+	if path := viper.ConfigFileUsed(); path != "" {
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			content, err := ioutil.ReadFile(path)
+			if err == nil {
+				err = yaml.Unmarshal(content, &file)
 			}
-			splitHostname := strings.Split(hostname, ".")
-			if len(splitHostname) < 3 {
-				log.Warn("Cannot auto detect domainname")
-			} else {
-				detectedDomainName = splitHostname[1] + "." + splitHostname[2]
+			if err != nil {
+				log.Fatal("Error reading config file: ", err)
 			}
 		}
+	}
+	return file
+}
 
-		if nodeName == "" {
-			if hostname == "" {
-				log.Fatal("Unable to detect hostname and no hostname provided")
-			}
-			log.Info("No hostname provided - auto detecting hostname")
-			nodeName = hostname
-		}
+// namingConventions merges the naming templates from the config file and the
+// command line, the command line taking precedence
+func namingConventions(cmd *cobra.Command, file configFile) naming.Conventions {
+	conventions := naming.Conventions{
+		Master:    file.Naming.Master,
+		Etcd:      file.Naming.Etcd,
+		Endpoint:  file.Naming.Endpoint,
+		Discovery: file.Naming.Discovery,
+	}
+	if masterNameTemplate != "" {
+		conventions.Master = masterNameTemplate
+	}
+	if etcdNameTemplate != "" {
+		conventions.Etcd = etcdNameTemplate
+	}
+	if endpointNameTemplate != "" {
+		conventions.Endpoint = endpointNameTemplate
+	}
+	if len(discoveryNameTemplates) > 0 {
+		conventions.Discovery = discoveryNameTemplates
+	}
+	if file.Naming.DiscoveryDomains != nil {
+		conventions.DiscoveryDomains = append([]string{}, *file.Naming.DiscoveryDomains...)
+	}
+	if cmd.Flags().Changed("discovery-domains") {
+		conventions.DiscoveryDomains = append([]string{}, splitList(discoveryDomains)...)
+	}
+	return conventions
+}
 
-		if domainName == "" {
-			if detectedDomainName == "" {
-				log.Fatal("Please specify a domain name for the cluster")
-			}
-			domainName = detectedDomainName
+// templateVars merges the template variables from the config file and the
+// command line, the command line taking precedence
+func templateVars(file configFile) b.TemplateVars {
+	merge := func(vars map[string]string, flag string, values []string) map[string]string {
+		m := map[string]string{}
+		for k, v := range vars {
+			m[k] = v
 		}
-
-		if addressList == "" {
-			addresses = n.GetMasterAddresses(dcName, clusterName, domainName, numberMasters, svcIP)
-			//log.Fatal("Please specify an list of IP addresses for the cluster")
-		} else {
-			addresses = addressList
+		for k, v := range parseKeyValues(flag, values) {
+			m[k] = v
 		}
+		return m
+	}
 
-		ipAddress = n.GetOutboundIP()
-
-		if token == "" {
-
-			generatedToken, err = t.GenerateToken()
+	return b.TemplateVars{
+		ExtStr:  merge(file.ExtVars, "--ext-str", extStr),
+		ExtCode: merge(file.ExtCode, "--ext-code", extCode),
+		TLAStr:  merge(file.TLAVars, "--tla-str", tlaStr),
+		TLACode: merge(file.TLACode, "--tla-code", tlaCode),
+	}
+}
 
-			if err != nil {
-				log.Fatal("Error generating bootstrap token", err)
-			}
-			token = generatedToken
+// validateConfig checks a rendered config before it's written, logging every
+// problem found
+func validateConfig(out []byte) {
+	err := v.Config(out)
+	if errs, ok := err.(v.Errors); ok {
+		for _, e := range errs {
+			log.Error(e)
 		}
+		log.Fatal("Generated kubeadm config is invalid, not writing it")
+	}
+	if err != nil {
+		log.Fatal("Error validating kubeadm config: ", err)
+	}
+}
 
-		// populate jsonnet extvars
-		vm.ExtVar("datacenter", dcName)
-		vm.ExtVar("clustername", clusterName)
-		vm.ExtVar("domainname", domainName)
-		vm.ExtVar("nodename", nodeName)
-		vm.ExtVar("cloudprovider", cloudProvider)
-		vm.ExtVar("ipaddress", ipAddress)
-		vm.ExtVar("addresslist", addresses)
-		vm.ExtVar("token", token)
-		vm.ExtVar("number_masters", strconv.Itoa(numberMasters))
+// checkPolicy prints the benchmark report for a config, unless logging is
+// suppressed, and returns false if it fails any rule
+func checkPolicy(out []byte, w io.Writer) bool {
+	rules, err := p.Load(benchmark, policyDir)
+	if err != nil {
+		log.Fatal("Error loading policy rules: ", err)
+	}
 
-		// evaluate jsonnet snippet
-		out, err := vm.EvaluateSnippet("file", tmpl)
+	report, err := rules.Evaluate(out)
+	if err != nil {
+		log.Fatal("Error checking policy rules: ", err)
+	}
 
-		if err != nil {
-			log.Fatal(err)
-		}
+	if !quiet {
+		report.Write(w)
+	}
 
-		if !dryrun {
-			// write the kubeadm file to disk
-			outFile := []byte(out)
-			err = ioutil.WriteFile(kubeadmFile, outFile, 0644)
+	return report.Passed()
+}
 
-			if err != nil {
-				log.Fatal("Error writing kubeadm file", err)
-			}
+// writeConfig writes a rendered config to the kubeadm file, or stdout with
+// --dry-run
+func writeConfig(cmd *cobra.Command, out []byte) {
+	// the default file is named after the format
+	if outputFormat == b.FormatYAML && !cmd.Flags().Changed("kubeadmfile") {
+		kubeadmFile = strings.TrimSuffix(kubeadmFile, filepath.Ext(kubeadmFile)) + ".yaml"
+	}
 
-			log.Info("Wrote kubeadm file: ", kubeadmFile)
-		} else {
-			log.Info("Dry run specified, printing to stdout: ")
-			fmt.Println(out)
+	if !dryrun {
+		// write the kubeadm file to disk
+		err := ioutil.WriteFile(kubeadmFile, out, 0644)
+
+		if err != nil {
+			log.Fatal("Error writing kubeadm file", err)
 		}
 
-	},
+		log.Info("Wrote kubeadm file: ", kubeadmFile)
+	} else {
+		log.Info("Dry run specified, printing to stdout: ")
+		fmt.Println(string(out))
+	}
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
@@ -266,11 +442,57 @@ func init() {
 	RootCmd.PersistentFlags().StringVarP(&domainName, "domainname", "D", "", "domain name for nodes in cluster")
 	RootCmd.PersistentFlags().StringVarP(&kubeadmFile, "kubeadmfile", "f", "/etc/kubernetes/kubeadm.json", "path to kubeadm file to write")
 	RootCmd.PersistentFlags().StringVarP(&addressList, "addresslist", "a", "", "comma separated list of IP's for the cluster")
-	RootCmd.PersistentFlags().StringVarP(&svcIP, "svcip", "s", "10.96.0.1", "kubernetes service IP")
+	RootCmd.PersistentFlags().StringVarP(&svcIP, "svcip", "s", "", "kubernetes service IP (default is the first address of the service CIDR)")
 	RootCmd.PersistentFlags().IntVarP(&numberMasters, "number", "m", 3, "number of masters in the cluster")
+	RootCmd.PersistentFlags().StringVarP(&masterNameTemplate, "master-name-template", "", "", "Go template naming each master (default \""+naming.Default.Master+"\")")
+	RootCmd.PersistentFlags().StringVarP(&etcdNameTemplate, "etcd-name-template", "", "", "Go template naming each external etcd member (default \""+naming.Default.Etcd+"\")")
+	RootCmd.PersistentFlags().StringVarP(&endpointNameTemplate, "endpoint-name-template", "", "", "Go template for the name nodes reach the control plane on (default \""+naming.Default.Endpoint+"\")")
+	RootCmd.PersistentFlags().StringArrayVarP(&discoveryNameTemplates, "discovery-name-template", "", nil, "Go template for another name of the control plane added to the API server cert SANs, can be repeated (default the names in each discovery domain)")
+	RootCmd.PersistentFlags().StringVarP(&discoveryDomains, "discovery-domains", "", strings.Join(naming.Default.DiscoveryDomains, ","), "comma separated service discovery domains the control plane is registered in, empty for none")
+	RootCmd.PersistentFlags().BoolVarP(&verifyDiscoveryNames, "verify-discovery-names", "", false, "leave discovery names that don't resolve out of the API server cert SANs")
+	RootCmd.PersistentFlags().StringVarP(&masterSRV, "master-srv", "", "", "SRV name to discover the masters from, e.g. a consul service, instead of their numbered names")
+	RootCmd.PersistentFlags().DurationVarP(&dnsTimeout, "dns-timeout", "", 10*time.Second, "how long looking up the masters in DNS can take")
 	RootCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "kubernetes bootstrap token")
+	RootCmd.PersistentFlags().DurationVarP(&tokenTTL, "token-ttl", "", 0, "how long the bootstrap token is valid, 0 never expires")
+	RootCmd.PersistentFlags().StringVarP(&tokenUsages, "token-usages", "", strings.Join(t.Usages, ","), "comma separated uses of the bootstrap token ("+strings.Join(t.Usages, ", ")+")")
+	RootCmd.PersistentFlags().StringVarP(&tokenGroups, "token-groups", "", t.DefaultGroup, "comma separated extra groups the bootstrap token authenticates as")
+	RootCmd.PersistentFlags().StringVarP(&tokenDescription, "token-description", "", "", "description of the bootstrap token")
 	RootCmd.PersistentFlags().BoolVarP(&dryrun, "dry-run", "", false, "output the kubeadm config to stdout instead of a file")
 	RootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "", false, "suppress logging output")
+	RootCmd.PersistentFlags().StringVarP(&caCert, "ca-cert", "", "/etc/kubernetes/puppet/ca.pem", "path to the cluster CA certificate, used for token discovery")
+	RootCmd.PersistentFlags().BoolVarP(&skipCAVerification, "unsafe-skip-ca-verification", "", false, "let join configs skip verifying the cluster CA if its certificate is missing")
+	RootCmd.PersistentFlags().StringVarP(&etcdMode, "etcd-mode", "", "external", "etcd topology: external, or local for stacked etcd on the masters")
+	RootCmd.PersistentFlags().IntVarP(&etcdCount, "etcd-count", "", 3, "number of external etcd members")
+	RootCmd.PersistentFlags().StringVarP(&etcdEndpoints, "etcd-endpoints", "", "", "comma separated list of external etcd endpoints, overrides --etcd-count")
+	RootCmd.PersistentFlags().StringVarP(&etcdCAFile, "etcd-cafile", "", "/etc/kubernetes/puppet/ca.pem", "CA certificate for external etcd")
+	RootCmd.PersistentFlags().StringVarP(&etcdCertFile, "etcd-certfile", "", "/etc/kubernetes/puppet/cert.pem", "client certificate for external etcd")
+	RootCmd.PersistentFlags().StringVarP(&etcdKeyFile, "etcd-keyfile", "", "/etc/kubernetes/puppet/key.pem", "client key for external etcd")
+	RootCmd.PersistentFlags().StringVarP(&etcdDataDir, "etcd-datadir", "", "/var/lib/etcd", "data directory for local etcd")
+	RootCmd.PersistentFlags().StringArrayVarP(&etcdArgs, "etcd-arg", "", nil, "extra arg for local etcd as key=value, can be repeated")
+	RootCmd.PersistentFlags().StringVarP(&podCIDR, "pod-cidr", "", "", "pod network CIDR (default is the CNI preset's)")
+	RootCmd.PersistentFlags().StringVarP(&serviceCIDR, "service-cidr", "", "10.96.0.0/12", "service network CIDR")
+	RootCmd.PersistentFlags().StringVarP(&dnsDomain, "dns-domain", "", "cluster.local", "cluster DNS domain")
+	RootCmd.PersistentFlags().StringVarP(&cni, "cni", "", "", "CNI plugin to preset the pod network for ("+strings.Join(b.SupportedCNIs(), ", ")+")")
+	RootCmd.PersistentFlags().StringVarP(&factProviders, "fact-providers", "", f.DefaultOrder, "comma separated fact providers to detect node facts from, highest precedence first")
+	RootCmd.PersistentFlags().StringVarP(&factsFile, "facts-file", "", "/etc/kubeadm-bootstrap/facts.yaml", "YAML or JSON file of static facts for the file fact provider")
+	RootCmd.PersistentFlags().StringArrayVarP(&facterMappings, "facter-fact", "", nil, "map a fact to a facter fact as fact=facter.fact, e.g. ipaddress=networking.ip, can be repeated")
+	RootCmd.PersistentFlags().StringVarP(&advertiseInterface, "advertise-interface", "", "", "network interface to take the node address from, e.g. eth1")
+	RootCmd.PersistentFlags().StringVarP(&advertiseCIDR, "advertise-cidr", "", "", "subnet to take the node address from, e.g. 10.20.0.0/16, or an IPv4 and an IPv6 subnet for dual-stack")
+	RootCmd.PersistentFlags().StringVarP(&probeTarget, "probe-target", "", "", "host:port whose route picks the node address when it isn't otherwise known, no traffic is sent (default 8.8.8.8:80)")
+	RootCmd.PersistentFlags().StringVarP(&apiVersion, "api-version", "", "v1alpha1", "kubeadm config API version to generate ("+strings.Join(b.SupportedAPIVersions(), ", ")+")")
+	RootCmd.PersistentFlags().StringVarP(&kubernetesVersion, "kubernetes-version", "", "", "kubernetes release to configure, e.g. v1.18.20, control plane args are translated for it (default is the API version's release)")
+	RootCmd.PersistentFlags().BoolVarP(&components, "components", "", false, "also render hardened KubeletConfiguration and KubeProxyConfiguration for the bootstrap master")
+	RootCmd.PersistentFlags().StringVarP(&templateFile, "template", "", "", "jsonnet template to render instead of the embedded one, which it can import as kubeadm-bootstrap/<file>")
+	RootCmd.PersistentFlags().StringArrayVarP(&jpath, "jpath", "J", nil, "directory to search for jsonnet imports before the embedded library, can be repeated")
+	RootCmd.PersistentFlags().StringArrayVarP(&extStr, "ext-str", "", nil, "pass a string ext var to the template as key=value, can be repeated")
+	RootCmd.PersistentFlags().StringArrayVarP(&extCode, "ext-code", "", nil, "pass a jsonnet code ext var to the template as key=expr, can be repeated")
+	RootCmd.PersistentFlags().StringArrayVarP(&tlaStr, "tla-str", "", nil, "pass a string top-level arg to the template as key=value, can be repeated")
+	RootCmd.PersistentFlags().StringArrayVarP(&tlaCode, "tla-code", "", nil, "pass a jsonnet code top-level arg to the template as key=expr, can be repeated")
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output-format", "o", b.FormatJSON, "format to write the kubeadm config in: json or yaml")
+	RootCmd.PersistentFlags().StringVarP(&benchmark, "benchmark", "", p.DefaultBenchmark, "policy rule set to check the config against")
+	RootCmd.PersistentFlags().StringVarP(&policyDir, "policy-dir", "", "/etc/kubeadm-bootstrap/policies", "directory of YAML policy rule files, extending the built in rule sets")
+	RootCmd.PersistentFlags().BoolVarP(&enforce, "enforce", "", false, "refuse to write a config that fails any policy rule")
+	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable debug logging, e.g. template variables that aren't used")
 
 }
 
@@ -278,11 +500,12 @@ func init() {
 func initConfig() {
 	if cfgFile != "" { // enable ability to specify config file via flag
 		viper.SetConfigFile(cfgFile)
+	} else {
+		// setting the name would override --config
+		viper.SetConfigName(".kubeadm-bootstrap") // name of config file (without extension)
+		viper.AddConfigPath("$HOME")              // adding home directory as first search path
 	}
-
-	viper.SetConfigName(".kubeadm-bootstrap") // name of config file (without extension)
-	viper.AddConfigPath("$HOME")              // adding home directory as first search path
-	viper.AutomaticEnv()                      // read in environment variables that match
+	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
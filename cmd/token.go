@@ -0,0 +1,248 @@
+// Copyright © 2018 Lee Briggs <lee@leebriggs.co.uk>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	b "github.com/apptio/kubeadm-bootstrap/pkg/bootstrap"
+	k "github.com/apptio/kubeadm-bootstrap/pkg/kube"
+	t "github.com/apptio/kubeadm-bootstrap/pkg/token"
+)
+
+var kubeconfig string
+var kubeContext string
+var printJoinConfig bool
+var clusterTokenTTL time.Duration
+
+// tokenCmd represents the token command
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "manage the bootstrap tokens of a running cluster",
+	Long: `Create, list, delete and rotate the bootstrap tokens of a running cluster, which
+are Secrets in kube-system. The API server is reached using a kubeconfig, by
+default $KUBECONFIG or ` + k.DefaultKubeconfig,
+}
+
+// tokenCreateCmd represents the token create command
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a bootstrap token in the cluster",
+	Long: `Create a bootstrap token in the cluster, using --token or a generated one, with
+the --token-* options. The token is printed, or with --print-join-config the
+join config using it`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		opts := tokenOptions().WithDefaults()
+		opts.TTL = clusterTokenTTL
+		if err := opts.Validate(); err != nil {
+			log.Fatal(err)
+		}
+
+		newToken := token
+		if newToken == "" {
+			var err error
+			newToken, err = t.GenerateToken()
+			if err != nil {
+				log.Fatal("Error generating token: ", err)
+			}
+		}
+		bt := t.NewBootstrapToken(newToken, opts, time.Now())
+
+		if err := t.Create(context.Background(), secretClient(), bt); err != nil {
+			log.Fatal("Error creating bootstrap token: ", err)
+		}
+		log.Info("Created bootstrap token ", bt.ID(), expiry(bt))
+
+		// only render the join config once the token exists, so it is never
+		// printed with a token nodes can't join with
+		var out []byte
+		if printJoinConfig {
+			out = renderJoin(cmd, role, bt.Token)
+		}
+
+		printToken(bt, out)
+
+	},
+}
+
+// tokenListCmd represents the token list command
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the bootstrap tokens of the cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		tokens, err := t.List(context.Background(), secretClient())
+		if err != nil {
+			log.Fatal("Error listing bootstrap tokens: ", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "TOKEN\tTTL\tEXPIRES\tUSAGES\tDESCRIPTION\tEXTRA GROUPS")
+		for _, bt := range tokens {
+			ttl, expires := "<forever>", "<never>"
+			if !bt.Expires.IsZero() {
+				ttl = "<expired>"
+				if left := time.Until(bt.Expires); left > 0 {
+					ttl = left.Round(time.Second).String()
+				}
+				expires = bt.Expires.Format(time.RFC3339)
+			}
+			description := bt.Description
+			if description == "" {
+				description = "<none>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", bt.Token, ttl, expires, strings.Join(bt.Usages, ","), description, strings.Join(bt.Groups, ","))
+		}
+		w.Flush()
+
+	},
+}
+
+// tokenDeleteCmd represents the token delete command
+var tokenDeleteCmd = &cobra.Command{
+	Use:   "delete <token-id>...",
+	Short: "delete bootstrap tokens from the cluster",
+	Long: `Delete bootstrap tokens from the cluster, given by their ID or the whole token.
+Nodes can no longer join with them`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		client := secretClient()
+		for _, id := range args {
+			if err := t.Delete(context.Background(), client, id); err != nil {
+				log.Fatal("Error deleting bootstrap token: ", err)
+			}
+			log.Info("Deleted bootstrap token ", t.ID(id))
+		}
+
+	},
+}
+
+// tokenRotateCmd represents the token rotate command
+var tokenRotateCmd = &cobra.Command{
+	Use:   "rotate <token-id>",
+	Short: "replace a bootstrap token of the cluster with a new one",
+	Long: `Replace a bootstrap token, given by its ID or the whole token, with a generated
+one that has the same usages, groups and description and is valid for
+--token-ttl. The new token is created before the old one is deleted, and is
+printed, or with --print-join-config the join config using it`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		client := secretClient()
+		old, err := t.Get(context.Background(), client, args[0])
+		if err != nil {
+			log.Fatal("Error reading bootstrap token: ", err)
+		}
+
+		opts := t.Options{
+			TTL:         clusterTokenTTL,
+			Usages:      old.Usages,
+			Groups:      old.Groups,
+			Description: old.Description,
+		}
+		if err := opts.Validate(); err != nil {
+			log.Fatal(err)
+		}
+
+		newToken, err := t.GenerateToken()
+		if err != nil {
+			log.Fatal("Error generating token: ", err)
+		}
+		bt := t.NewBootstrapToken(newToken, opts, time.Now())
+
+		if err := t.Rotate(context.Background(), client, old.ID(), bt); err != nil {
+			log.Fatal("Error rotating bootstrap token: ", err)
+		}
+		log.Info("Replaced bootstrap token ", old.ID(), " with ", bt.ID(), expiry(bt))
+
+		var out []byte
+		if printJoinConfig {
+			out = renderJoin(cmd, role, bt.Token)
+		}
+
+		printToken(bt, out)
+
+	},
+}
+
+// secretClient connects to the API server of the kubeconfig
+func secretClient() k.SecretClient {
+	path := k.KubeconfigPath(kubeconfig)
+	cfg, err := k.LoadConfig(path, kubeContext)
+	if err != nil {
+		log.Fatal("Error loading kubeconfig: ", err)
+	}
+	log.Debug("Using API server ", cfg.Server, " from ", path)
+	return k.NewClient(cfg)
+}
+
+// printToken prints a token, or the join config using it if there is one
+func printToken(bt t.BootstrapToken, joinConfig []byte) {
+	if joinConfig != nil {
+		fmt.Println(string(joinConfig))
+		return
+	}
+	fmt.Println(bt.Token)
+}
+
+// expiry describes when a token expires, with a leading comma
+func expiry(bt t.BootstrapToken) string {
+	if bt.Expires.IsZero() {
+		return ", which never expires"
+	}
+	return ", which expires at " + bt.Expires.Format(time.RFC3339)
+}
+
+func init() {
+	RootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenDeleteCmd, tokenRotateCmd)
+
+	tokenCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "", "", "kubeconfig to reach the API server with (default $KUBECONFIG or "+k.DefaultKubeconfig+")")
+	tokenCmd.PersistentFlags().StringVarP(&kubeContext, "context", "", "", "kubeconfig context to use (default the current context)")
+	// tokens created in a running cluster expire like kubeadm's, unlike the
+	// bootstrap master's token
+	tokenCmd.PersistentFlags().DurationVarP(&clusterTokenTTL, "token-ttl", "", t.DefaultTTL, "how long the bootstrap token is valid, 0 never expires")
+
+	for _, c := range []*cobra.Command{tokenCreateCmd, tokenRotateCmd} {
+		c.Flags().BoolVarP(&printJoinConfig, "print-join-config", "", false, "print the join config using the token instead of the token")
+		c.Flags().StringVarP(&role, "role", "r", b.RoleWorker, "role of the node the join config is for ("+b.RoleControlPlane+", "+b.RoleWorker+")")
+	}
+
+}
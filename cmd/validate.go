@@ -0,0 +1,91 @@
+// Copyright © 2018 Lee Briggs <lee@leebriggs.co.uk>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	v "github.com/apptio/kubeadm-bootstrap/pkg/validate"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "check an existing kubeadm config file",
+	Long: `Run the checks made on generated configs against an existing JSON or YAML
+kubeadm config file, given with -f. Every problem found is printed to stderr,
+and the exit code is non-zero if there are any. The policy report is printed
+too, and with --enforce, failing any policy rule is also an error`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		setupLogging()
+
+		if !validateFile(kubeadmFile, os.Stderr) {
+			os.Exit(1)
+		}
+
+	},
+}
+
+// validateFile checks a kubeadm file, writing every problem and the policy
+// report to w, and returns false if the file is invalid or, with --enforce,
+// fails the policy
+func validateFile(filename string, w io.Writer) bool {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatal("Error reading kubeadm file: ", err)
+	}
+
+	err = v.Config(data)
+	errs, invalid := err.(v.Errors)
+	if err != nil && !invalid {
+		log.Fatal("Error validating kubeadm file: ", err)
+	}
+	for _, e := range errs {
+		fmt.Fprintln(w, e)
+	}
+
+	// the policy is checked even if the file is invalid, so everything
+	// wrong with it is reported at once
+	passed := checkPolicy(data, w)
+
+	if invalid {
+		log.Error("Kubeadm file is invalid: ", filename)
+		return false
+	}
+	if !passed && enforce {
+		log.Error("Kubeadm file doesn't comply with ", benchmark, ": ", filename)
+		return false
+	}
+
+	log.Info("Kubeadm file is valid: ", filename)
+	return true
+}
+
+func init() {
+	RootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestValidateProcess runs the command line given in KUBEADM_BOOTSTRAP_ARGS,
+// so tests can check what it writes and its exit code
+func TestValidateProcess(t *testing.T) {
+	args := os.Getenv("KUBEADM_BOOTSTRAP_ARGS")
+	if args == "" {
+		return
+	}
+	RootCmd.SetArgs(strings.Fields(args))
+	if err := RootCmd.Execute(); err != nil {
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+// runValidate runs validate against a kubeadm file in a new process, and
+// returns its stdout, stderr and exit code
+func runValidate(t *testing.T, config string) (string, string, int) {
+	dir, err := ioutil.TempDir("", "validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// no policy overrides
+	policyDir := filepath.Join(dir, "policies")
+	if err := os.Mkdir(policyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "kubeadm.yaml")
+	if err := ioutil.WriteFile(filename, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestValidateProcess$")
+	cmd.Env = append(os.Environ(), "KUBEADM_BOOTSTRAP_ARGS=validate --policy-dir "+policyDir+" -f "+filename)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err = cmd.Run()
+	code := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.Sys().(syscall.WaitStatus).ExitStatus()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), code
+}
+
+func TestValidate(t *testing.T) {
+	stdout, stderr, code := runValidate(t, `
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: InitConfiguration
+bootstrapTokens:
+- token: abcdef
+---
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+apiServer:
+  certSAN: [dc1-k1.example.com]
+  extraArgs:
+    apiserver-count: "0"
+`)
+
+	if code != 1 {
+		t.Errorf("got exit code %d, want 1", code)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	for _, problem := range []string{
+		`InitConfiguration.bootstrapTokens[0].token: invalid bootstrap token "abcdef"`,
+		"ClusterConfiguration.apiServer.certSAN: unknown field",
+		`ClusterConfiguration.apiServer.extraArgs.apiserver-count: must be an integer of at least 1, got "0"`,
+		// the policy report of the invalid file
+		"Benchmark cis-1.6:",
+	} {
+		if !strings.Contains(stderr, problem) {
+			t.Errorf("expected %q on stderr, got\n%s", problem, stderr)
+		}
+	}
+
+	stdout, stderr, code = runValidate(t, `
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: JoinConfiguration
+discovery:
+  file:
+    kubeConfigPath: /etc/kubernetes/discovery.conf
+`)
+	if code != 0 || stdout != "" || !strings.Contains(stderr, "Kubeadm file is valid") {
+		t.Errorf("got exit code %d, stdout %q and stderr\n%s", code, stdout, stderr)
+	}
+}
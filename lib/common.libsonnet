@@ -0,0 +1,169 @@
+// Inputs and derived values shared by every kubeadm API version.
+// Everything here is hidden so each version library decides what to render.
+{
+
+    string_to_int(s)::
+        local char_to_int(c) = std.codepoint(c) - std.codepoint("0");
+        local digits = std.map(char_to_int, std.stringChars(s));
+        std.foldr(function(x, y) x + y,
+                  std.makeArray(std.length(digits),
+                                function(x) digits[std.length(digits) - x - 1] * std.pow(10, x)),
+                  0),
+
+    // splitList splits a comma separated list, which may be empty
+    splitList(s):: if s == "" then [] else std.split(s, ","),
+
+    // dedupe removes repeated items from a list, keeping the first of each
+    dedupe(list):: std.foldl(function(seen, x) if std.setMember(x, std.set(seen)) then seen else seen + [x], list, []),
+
+    // hostPort joins a host and a port, bracketing IPv6 addresses
+    hostPort(host, port)::
+        if std.length(std.split(host, ":")) > 1 then
+            "[" + host + "]:" + std.toString(port)
+        else
+            host + ":" + std.toString(port),
+
+
+    // Required arguments for this template
+    k8sVersion:: "v1.8.4",
+    // targetVersion is the kubernetes release to configure, the API
+    // version's default unless one is given
+    targetVersion:: if std.extVar("kubernetes_version") != "" then std.extVar("kubernetes_version") else $.k8sVersion,
+    clusterName:: std.extVar("clustername"),
+    addressList:: std.split(std.extVar("addresslist"), ","),
+
+    datacenterName:: std.extVar("datacenter"),
+
+    domainName:: std.extVar("domainname"),
+
+    nodeName:: std.extVar("nodename"),
+
+    cloudProvider:: std.extVar("cloudprovider"),
+
+    ipAddress:: std.extVar("ipaddress"),
+    // only set for dual-stack clusters
+    ipAddress6:: std.extVar("ipaddress6"),
+
+    token:: std.extVar("token"),
+    tokenTTL:: std.extVar("token_ttl"),
+    tokenUsages:: $.splitList(std.extVar("token_usages")),
+    tokenGroups:: $.splitList(std.extVar("token_groups")),
+    tokenDescription:: std.extVar("token_description"),
+
+    numberMasters:: std.extVar("number_masters"),
+
+    // Go text/template strings naming the masters, etcd members and control
+    // plane, rendered by the name native function so they match the lookups
+    naming:: std.extVar("naming"),
+    namingVars(index=0):: {
+        Datacenter: $.datacenterName,
+        Cluster: $.clusterName,
+        Domain: $.domainName,
+        Index: index,
+    },
+    nameOf(template, index=0):: std.native("name")(template, $.namingVars(index)),
+
+    // discovery names that were verified and didn't resolve
+    skippedDiscoveryNames:: std.set($.splitList(std.extVar("skipped_discovery_names"))),
+
+    // public key pin of the cluster CA, empty if the CA wasn't available
+    caCertHash:: std.extVar("cacerthash"),
+    caCertHashes:: if $.caCertHash == "" then [] else [$.caCertHash],
+    // joining nodes only skip verifying the cluster CA if asked to
+    unsafeSkipCAVerification:: std.extVar("skip_ca_verify") == "true",
+
+    // only set when rendering a join config: control-plane or worker
+    role:: std.extVar("role"),
+
+    apiServerExtraArgs:: {
+        "etcd-prefix": $.datacenterName + "-" + $.clusterName,
+        profiling: "false",
+        //"admission-control": "Initializers,NamespaceLifecycle,LimitRanger,ServiceAccount,PersistentVolumeLabel,DefaultStorageClass,DefaultTolerationSeconds,NodeRestriction,ResourceQuota,AlwaysPullImages,DenyEscalatingExec,SecurityContextDeny,PodSecurityPolicy",
+        "audit-log-path": "-",
+        "audit-log-maxage": "30",
+        "audit-log-maxbackup": "10",
+        "audit-log-maxsize": "100",
+        "service-account-lookup": "true",
+        "repair-malformed-updates": "false",
+        "apiserver-count": $.numberMasters,
+        [if $.cloudProvider != "" then "cloud-provider"]: $.cloudProvider,
+        "advertise-address": $.ipAddress,
+        "request-timeout": "300s",
+        "admission-control": "Initializers,NamespaceLifecycle,LimitRanger,ServiceAccount,PersistentVolumeLabel,DefaultStorageClass,DefaultTolerationSeconds,NodeRestriction,ResourceQuota,AlwaysPullImages,DenyEscalatingExec,SecurityContextDeny",
+    },
+
+    controllerManagerExtraArgs:: {
+        profiling: "false",
+        "terminated-pod-gc-threshold": "10",
+        [if $.cloudProvider != "" then "cloud-provider"]: $.cloudProvider,
+        "address": "0.0.0.0",
+        [if $.allocateNodeCIDRs then "allocate-node-cidrs"]: "true",
+        [if $.allocateNodeCIDRs then "cluster-cidr"]: $.podSubnet,
+    },
+
+    schedulerExtraArgs:: {
+        profiling: "false",
+        "address": "0.0.0.0",
+    },
+
+    // cluster networks. the pod subnet is empty unless it was given or a CNI
+    // preset was chosen
+    podSubnet:: std.extVar("pod_subnet"),
+    serviceSubnet:: std.extVar("service_subnet"),
+    dnsDomain:: std.extVar("dns_domain"),
+    allocateNodeCIDRs:: std.extVar("allocate_node_cidrs") == "true",
+    // dual-stack subnets are an IPv4 and an IPv6 CIDR separated by a comma
+    dualStack:: std.extVar("dual_stack") == "true",
+
+    networking:: {
+        serviceSubnet: $.serviceSubnet,
+        [if $.podSubnet != "" then "podSubnet"]: $.podSubnet,
+        dnsDomain: $.dnsDomain,
+    },
+
+    // external etcd uses etcdCount members named after the cluster unless
+    // endpoints are given explicitly. local etcd is stacked on the masters.
+    etcdMode:: std.extVar("etcd_mode"),
+    externalEtcd:: $.etcdMode == "external",
+    localEtcd:: $.etcdMode == "local",
+
+    etcdCount:: $.string_to_int(std.extVar("etcd_count")),
+
+    etcdEndpoints::
+        if std.extVar("etcd_endpoints") != "" then
+            std.split(std.extVar("etcd_endpoints"), ",")
+        else
+            std.makeArray($.etcdCount, function(count) "https://" + $.hostPort($.nameOf($.naming.etcd, count + 1), 2379)),
+
+    etcdCAFile:: std.extVar("etcd_cafile"),
+    etcdCertFile:: std.extVar("etcd_certfile"),
+    etcdKeyFile:: std.extVar("etcd_keyfile"),
+
+    etcdDataDir:: std.extVar("etcd_datadir"),
+    etcdExtraArgs:: std.extVar("etcd_extra_args"),
+    etcdServerCertSANs:: std.flattenArrays([$.apiServerNames, $.apiServerIPs]),
+
+    apiServerIPs:: $.addressList,
+
+    apiServerNames:: std.makeArray($.string_to_int($.numberMasters), function(count) $.nameOf($.naming.master, count + 1)),
+
+    apiServerDiscoveryNames:: [
+        name
+        for name in std.native("discoveryNames")($.naming, $.namingVars())
+        if !std.setMember(name, $.skippedDiscoveryNames)
+    ],
+
+    // the name other nodes use to reach the control plane
+    apiServerEndpointName:: std.native("endpointName")($.naming, $.namingVars()),
+    apiServerPort:: 6443,
+    apiServerEndpoint:: $.hostPort($.apiServerEndpointName, $.apiServerPort),
+
+    // the endpoint is always in the SANs, so nodes can join through it
+    apiServerCertSANs:: $.dedupe(std.flattenArrays([
+        $.apiServerNames,
+        $.apiServerIPs,
+        $.apiServerDiscoveryNames,
+        [$.apiServerEndpointName],
+    ])),
+
+}
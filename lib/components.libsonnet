@@ -0,0 +1,65 @@
+// KubeletConfiguration and KubeProxyConfiguration for the nodes of the
+// cluster, hardened to the CIS benchmark. Mixed into each kubeadm API version
+// library after common.libsonnet, and only rendered if components is set.
+{
+
+    components:: std.extVar("components") == "true",
+
+    // the kubelet takes the cloud provider as a flag, KubeletConfiguration
+    // has no field for it
+    kubeletExtraArgs:: {
+        [if $.cloudProvider != "" then "cloud-provider"]: $.cloudProvider,
+    },
+
+    // clusterDNS is left for kubeadm to fill in from the service subnet
+    kubeletConfigurationSpec:: {
+        authentication: {
+            anonymous: {
+                enabled: false,
+            },
+            webhook: {
+                enabled: true,
+            },
+            x509: {
+                clientCAFile: "/etc/kubernetes/pki/ca.crt",
+            },
+        },
+        authorization: {
+            mode: "Webhook",
+        },
+        clusterDomain: $.dnsDomain,
+        readOnlyPort: 0,
+        protectKernelDefaults: true,
+        makeIPTablesUtilChains: true,
+        streamingConnectionIdleTimeout: "5m0s",
+        rotateCertificates: true,
+        serverTLSBootstrap: true,
+        tlsCipherSuites: [
+            "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+            "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+            "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+            "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+            "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+            "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305",
+        ],
+    },
+
+    kubeProxyConfigurationSpec:: {
+        bindAddress: "0.0.0.0",
+        [if $.podSubnet != "" then "clusterCIDR"]: $.podSubnet,
+        healthzBindAddress: "0.0.0.0:10256",
+        metricsBindAddress: "127.0.0.1:10249",
+        mode: "iptables",
+    },
+
+    kubeletComponentConfig:: {
+        apiVersion: "kubelet.config.k8s.io/v1beta1",
+        kind: "KubeletConfiguration",
+    } + $.kubeletConfigurationSpec,
+
+    kubeProxyComponentConfig:: {
+        apiVersion: "kubeproxy.config.k8s.io/v1alpha1",
+        kind: "KubeProxyConfiguration",
+    } + $.kubeProxyConfigurationSpec,
+
+}
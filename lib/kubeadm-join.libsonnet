@@ -0,0 +1,14 @@
+// kubeadm.k8s.io/v1alpha1 NodeConfiguration for workers (kubeadm 1.8 - 1.10)
+(import "common.libsonnet") + {
+
+    apiVersion: "kubeadm.k8s.io/v1alpha1",
+    kind: "NodeConfiguration",
+    nodeName::: super.nodeName,
+    token::: super.token,
+    discoveryTokenAPIServers: [
+        $.apiServerEndpoint,
+    ],
+    discoveryTokenCACertHashes: $.caCertHashes,
+    discoveryTokenUnsafeSkipCAVerification: $.unsafeSkipCAVerification,
+
+}
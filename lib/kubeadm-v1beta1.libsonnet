@@ -0,0 +1,100 @@
+// kubeadm.k8s.io/v1beta1 (kubeadm 1.13 - 1.14)
+// The master config is split into InitConfiguration and ClusterConfiguration
+// documents, rendered together from the master array along with the component
+// configs. Additional nodes get a JoinConfiguration from the join array.
+(import "common.libsonnet") + (import "components.libsonnet") + {
+
+    kubeadmAPIVersion:: "kubeadm.k8s.io/v1beta1",
+    k8sVersion:: "v1.13.12",
+
+    // advertise-address is per node, it comes from localAPIEndpoint instead
+    local clusterArgs(args) = { [k]: args[k] for k in std.objectFields(args) if k != "advertise-address" },
+
+    // dual-stack nodes outside a cloud have to tell the kubelet both their
+    // addresses, the cloud provider knows them otherwise
+    kubeletExtraArgs:: super.kubeletExtraArgs + {
+        [if $.dualStack && $.cloudProvider == "" then "node-ip"]: $.ipAddress + "," + $.ipAddress6,
+    },
+
+    nodeRegistration:: {
+        name: $.nodeName,
+        [if std.length(std.objectFields($.kubeletExtraArgs)) > 0 then "kubeletExtraArgs"]: $.kubeletExtraArgs,
+    },
+
+    initConfiguration:: {
+        apiVersion: $.kubeadmAPIVersion,
+        kind: "InitConfiguration",
+        bootstrapTokens: [
+            {
+                token: $.token,
+                ttl: $.tokenTTL,
+                usages: $.tokenUsages,
+                groups: $.tokenGroups,
+                [if $.tokenDescription != "" then "description"]: $.tokenDescription,
+            },
+        ],
+        nodeRegistration: $.nodeRegistration,
+        localAPIEndpoint: {
+            advertiseAddress: $.ipAddress,
+            bindPort: $.apiServerPort,
+        },
+    },
+
+    clusterConfiguration:: {
+        apiVersion: $.kubeadmAPIVersion,
+        kind: "ClusterConfiguration",
+        kubernetesVersion: $.targetVersion,
+        clusterName: $.clusterName,
+        controlPlaneEndpoint: $.apiServerEndpoint,
+        networking: $.networking,
+        apiServer: {
+            extraArgs: clusterArgs($.apiServerExtraArgs),
+            certSANs: $.apiServerCertSANs,
+        },
+        controllerManager: {
+            extraArgs: $.controllerManagerExtraArgs,
+        },
+        scheduler: {
+            extraArgs: $.schedulerExtraArgs,
+        },
+        etcd: {
+            [if $.externalEtcd then "external"]: {
+                endpoints: $.etcdEndpoints,
+                caFile: $.etcdCAFile,
+                certFile: $.etcdCertFile,
+                keyFile: $.etcdKeyFile,
+            },
+            [if $.localEtcd then "local"]: {
+                dataDir: $.etcdDataDir,
+                extraArgs: $.etcdExtraArgs,
+                serverCertSANs: $.etcdServerCertSANs,
+                peerCertSANs: $.etcdServerCertSANs,
+            },
+        },
+    },
+
+    joinConfiguration:: {
+        apiVersion: $.kubeadmAPIVersion,
+        kind: "JoinConfiguration",
+        nodeRegistration: $.nodeRegistration,
+        discovery: {
+            bootstrapToken: {
+                token: $.token,
+                apiServerEndpoint: $.apiServerEndpoint,
+                [if std.length($.caCertHashes) > 0 then "caCertHashes"]: $.caCertHashes,
+                unsafeSkipCAVerification: $.unsafeSkipCAVerification,
+            },
+        },
+        [if $.role == "control-plane" then "controlPlane"]: {
+            localAPIEndpoint: {
+                advertiseAddress: $.ipAddress,
+                bindPort: $.apiServerPort,
+            },
+        },
+    },
+
+    master:: [$.initConfiguration, $.clusterConfiguration] +
+        (if $.components then [$.kubeletComponentConfig, $.kubeProxyComponentConfig] else []),
+    join:: [$.joinConfiguration],
+
+}
@@ -0,0 +1,8 @@
+// kubeadm.k8s.io/v1beta2 (kubeadm 1.15 - 1.21)
+// Same layout as v1beta1 for everything this template sets.
+(import "kubeadm-v1beta1.libsonnet") + {
+
+    kubeadmAPIVersion:: "kubeadm.k8s.io/v1beta2",
+    k8sVersion:: "v1.18.20",
+
+}
@@ -0,0 +1,7 @@
+// kubeadm.k8s.io/v1beta3 (kubeadm 1.22+)
+(import "kubeadm-v1beta2.libsonnet") + {
+
+    kubeadmAPIVersion:: "kubeadm.k8s.io/v1beta3",
+    k8sVersion:: "v1.22.17",
+
+}
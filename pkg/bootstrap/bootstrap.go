@@ -0,0 +1,521 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	c "github.com/apptio/kubeadm-bootstrap/pkg/certs"
+	"github.com/apptio/kubeadm-bootstrap/pkg/compat"
+	f "github.com/apptio/kubeadm-bootstrap/pkg/facts"
+	"github.com/apptio/kubeadm-bootstrap/pkg/naming"
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+	t "github.com/apptio/kubeadm-bootstrap/pkg/token"
+)
+
+// The kinds of node a config can be generated for
+const (
+	// RoleMaster is the bootstrap master, which runs kubeadm init
+	RoleMaster = "master"
+	// RoleControlPlane is an additional master joining the cluster
+	RoleControlPlane = "control-plane"
+	// RoleWorker is a worker joining the cluster
+	RoleWorker = "worker"
+)
+
+// EtcdConfig describes the etcd topology
+type EtcdConfig struct {
+	// Mode is external, or local for stacked etcd on the masters
+	Mode string
+	// Count is the number of external etcd members, used to generate their
+	// names when Endpoints is empty
+	Count     int
+	Endpoints []string
+	CAFile    string
+	CertFile  string
+	KeyFile   string
+	// DataDir and ExtraArgs configure local etcd
+	DataDir   string
+	ExtraArgs map[string]string
+}
+
+// Config is the input used to generate a kubeadm config. Empty node facts are
+// detected when the config is resolved
+type Config struct {
+	// APIVersion is the kubeadm config API version, e.g. v1beta2
+	APIVersion string
+	// Role is the kind of node to generate a config for, defaults to RoleMaster
+	Role string
+
+	Datacenter  string
+	ClusterName string
+	DomainName  string
+	NodeName    string
+
+	// AddressList are the master IPs. If empty they are looked up in DNS,
+	// along with SvcIP. The masters are found from MasterSRV if it is set,
+	// otherwise from their numbered names
+	AddressList   []string
+	NumberMasters int
+	MasterSRV     string
+	// SvcIP is the kubernetes service IP. It defaults to, and must be, the
+	// first address of the service CIDR
+	SvcIP string
+
+	// Naming are the templates masters, etcd members and the control plane
+	// are named with, used for lookups and by the template. Empty fields use
+	// naming.Default
+	Naming naming.Conventions
+	// VerifyDiscoveryNames looks up the discovery names of the bootstrap
+	// master, and leaves the ones that don't resolve out of the cert SANs
+	VerifyDiscoveryNames bool
+
+	// Token is the bootstrap token. One is generated for the bootstrap master
+	// if empty, joining nodes must provide it
+	Token string
+	// TokenOptions are how the bootstrap master creates the token. nil never
+	// expires, and empty usages and groups use the default ones
+	TokenOptions *t.Options
+
+	// CACert is the path to the cluster CA certificate. Joining nodes always
+	// need it; for the bootstrap master a missing CA is skipped unless
+	// RequireCACert is set
+	CACert        string
+	RequireCACert bool
+	// SkipCAVerification lets joining nodes skip verifying the cluster CA
+	// during discovery when its certificate is missing
+	SkipCAVerification bool
+
+	Etcd EtcdConfig
+
+	Networking NetworkConfig
+
+	// Template is an on-disk jsonnet template rendered instead of the
+	// embedded one. JPath are extra directories searched for imports, ahead
+	// of the embedded library, and Vars are extra inputs for the template
+	Template string
+	JPath    []string
+	Vars     TemplateVars
+
+	// OutputFormat is FormatJSON or FormatYAML, defaults to FormatJSON
+	OutputFormat string
+
+	// KubernetesVersion is the kubernetes release to configure, e.g.
+	// v1.18.20. Defaults to the one the API version's template targets.
+	// Control plane args are translated for it
+	KubernetesVersion string
+
+	// Components also renders KubeletConfiguration and KubeProxyConfiguration
+	// for the bootstrap master
+	Components bool
+
+	// Facts detects node facts that weren't provided. Defaults to the
+	// default fact provider chain
+	Facts f.Chain
+
+	// Resolver looks up the masters in DNS, defaults to n.DefaultResolver.
+	// LookupTimeout limits how long that can take, if set
+	Resolver      n.Resolver
+	LookupTimeout time.Duration
+}
+
+// ResolvedConfig is a Config with every value filled in, ready to render
+type ResolvedConfig struct {
+	APIVersion string
+	Role       string
+
+	Datacenter    string
+	ClusterName   string
+	DomainName    string
+	NodeName      string
+	CloudProvider string
+	IPAddress     string
+	IPAddress6    string
+
+	Addresses     []string
+	NumberMasters int
+	SvcIP         string
+	Naming        naming.Conventions
+	// SkippedDiscoveryNames didn't resolve, and are left out of the cert SANs
+	SkippedDiscoveryNames []string
+
+	Token        string
+	TokenOptions t.Options
+	CACertHash   string
+	// SkipCAVerification is set if a joining node has no CA hash to verify
+	// the cluster CA with
+	SkipCAVerification bool
+
+	Etcd       EtcdConfig
+	Networking NetworkConfig
+
+	Template          string
+	JPath             []string
+	Vars              TemplateVars
+	OutputFormat      string
+	KubernetesVersion string
+	Components        bool
+
+	// Sources records which fact provider supplied each detected fact
+	Sources map[string]string
+}
+
+// validate checks the parts of the config that don't need detection
+func (cfg Config) validate() error {
+	tmpl, ok := apiVersions[cfg.APIVersion]
+	if !ok {
+		return fmt.Errorf("unsupported kubeadm API version %s, must be one of: %s", cfg.APIVersion, strings.Join(SupportedAPIVersions(), ", "))
+	}
+
+	switch cfg.Role {
+	case RoleMaster, RoleWorker:
+	case RoleControlPlane:
+		if !tmpl.controlPlaneJoin {
+			return fmt.Errorf("joining control plane nodes is not supported by kubeadm API version %s", cfg.APIVersion)
+		}
+	default:
+		return fmt.Errorf("unknown role %s, must be one of: %s, %s, %s", cfg.Role, RoleMaster, RoleControlPlane, RoleWorker)
+	}
+
+	if cfg.Networking.dualStack() && !tmpl.dualStack {
+		return fmt.Errorf("dual-stack networking is not supported by kubeadm API version %s", cfg.APIVersion)
+	}
+
+	if cfg.Components && cfg.Role != RoleMaster {
+		return fmt.Errorf("component configs can only be rendered for the bootstrap master, joining nodes get them from the cluster")
+	}
+
+	if cfg.OutputFormat != FormatJSON && cfg.OutputFormat != FormatYAML {
+		return fmt.Errorf("unknown output format %s, must be one of: %s, %s", cfg.OutputFormat, FormatJSON, FormatYAML)
+	}
+
+	if cfg.KubernetesVersion != "" {
+		if _, err := compat.Minor(cfg.KubernetesVersion); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ClusterName == "" {
+		return fmt.Errorf("please specify a cluster name")
+	}
+
+	if cfg.Role != RoleMaster && cfg.Token == "" {
+		return fmt.Errorf("please specify the bootstrap token for the cluster")
+	}
+
+	if cfg.Token != "" && !t.Valid(cfg.Token) {
+		return fmt.Errorf("invalid bootstrap token %q, must match [a-z0-9]{6}.[a-z0-9]{16}", cfg.Token)
+	}
+
+	if cfg.TokenOptions != nil {
+		if err := cfg.TokenOptions.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.Naming.WithDefaults().Validate(); err != nil {
+		return err
+	}
+
+	if cfg.Etcd.Mode != "external" && cfg.Etcd.Mode != "local" {
+		return fmt.Errorf("unknown etcd mode %s, must be one of: external, local", cfg.Etcd.Mode)
+	}
+
+	if cfg.Etcd.Mode == "external" && cfg.Etcd.Count < 1 && len(cfg.Etcd.Endpoints) == 0 {
+		return fmt.Errorf("please specify at least one etcd member")
+	}
+
+	return nil
+}
+
+// Resolve validates a config and detects everything that wasn't provided:
+// node facts, master addresses, the bootstrap token and the CA cert hash
+func Resolve(ctx context.Context, cfg Config) (ResolvedConfig, error) {
+	if cfg.Role == "" {
+		cfg.Role = RoleMaster
+	}
+	if cfg.OutputFormat == "" {
+		cfg.OutputFormat = FormatJSON
+	}
+
+	if err := cfg.validate(); err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	networking, err := cfg.Networking.resolve()
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	svcIP := networking.serviceIP()
+	if cfg.SvcIP != "" && cfg.SvcIP != svcIP {
+		return ResolvedConfig{}, fmt.Errorf("service IP %s must be %s, the first address of the service CIDR %s", cfg.SvcIP, svcIP, networking.ServiceSubnet)
+	}
+
+	chain := cfg.Facts
+	if chain == nil {
+		chain, err = f.NewChain(f.DefaultOrder, f.Options{FacterFacts: f.DefaultFacterFacts})
+		if err != nil {
+			return ResolvedConfig{}, err
+		}
+	}
+
+	r := ResolvedConfig{
+		APIVersion:        cfg.APIVersion,
+		Role:              cfg.Role,
+		Datacenter:        cfg.Datacenter,
+		ClusterName:       cfg.ClusterName,
+		DomainName:        cfg.DomainName,
+		NodeName:          cfg.NodeName,
+		Addresses:         cfg.AddressList,
+		NumberMasters:     cfg.NumberMasters,
+		SvcIP:             svcIP,
+		Naming:            cfg.Naming.WithDefaults(),
+		Token:             cfg.Token,
+		TokenOptions:      tokenOptions(cfg.TokenOptions),
+		Etcd:              cfg.Etcd,
+		Networking:        networking,
+		Template:          cfg.Template,
+		JPath:             cfg.JPath,
+		Vars:              cfg.Vars,
+		OutputFormat:      cfg.OutputFormat,
+		KubernetesVersion: cfg.KubernetesVersion,
+		Components:        cfg.Components,
+		Sources:           map[string]string{},
+	}
+
+	// lookup returns a fact from the first provider that knows it, or an
+	// empty string if none do
+	lookup := func(name string) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		v, ok, err := chain.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("error detecting %s: %v", name, err)
+		}
+		if !ok {
+			log.Warn("Cannot auto detect ", name)
+			return "", nil
+		}
+		log.WithField("source", v.Source).Info("Detected ", name, ": ", v.Value)
+		r.Sources[name] = v.Source
+		return v.Value, nil
+	}
+
+	if r.Datacenter == "" {
+		log.Info("Auto detecting dc name")
+		if r.Datacenter, err = lookup(f.Datacenter); err != nil {
+			return ResolvedConfig{}, err
+		}
+		if r.Datacenter == "" {
+			return ResolvedConfig{}, fmt.Errorf("no datacenter provided")
+		}
+	}
+
+	if r.CloudProvider, err = lookup(f.CloudProvider); err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	if r.NodeName == "" {
+		log.Info("No hostname provided - auto detecting hostname")
+		if r.NodeName, err = lookup(f.Hostname); err != nil {
+			return ResolvedConfig{}, err
+		}
+		if r.NodeName == "" {
+			return ResolvedConfig{}, fmt.Errorf("unable to detect hostname and no hostname provided")
+		}
+	}
+
+	if r.DomainName == "" {
+		if r.DomainName, err = lookup(f.Domain); err != nil {
+			return ResolvedConfig{}, err
+		}
+		if r.DomainName == "" {
+			return ResolvedConfig{}, fmt.Errorf("please specify a domain name for the cluster")
+		}
+	}
+
+	if r.IPAddress, err = lookup(f.IPAddress); err != nil {
+		return ResolvedConfig{}, err
+	}
+	if r.IPAddress == "" {
+		return ResolvedConfig{}, fmt.Errorf("unable to detect IP address")
+	}
+
+	if r.Networking.dualStack() {
+		if r.IPAddress6, err = lookup(f.IPAddress6); err != nil {
+			return ResolvedConfig{}, err
+		}
+		if r.IPAddress6 == "" {
+			return ResolvedConfig{}, fmt.Errorf("unable to detect IPv6 address, which dual-stack networking needs")
+		}
+		if ip := net.ParseIP(r.IPAddress); ip == nil || isIPv6(ip) {
+			return ResolvedConfig{}, fmt.Errorf("dual-stack networking needs an IPv4 node address, got %s", r.IPAddress)
+		}
+		if ip := net.ParseIP(r.IPAddress6); ip == nil || !isIPv6(ip) {
+			return ResolvedConfig{}, fmt.Errorf("invalid IPv6 node address %s", r.IPAddress6)
+		}
+	}
+
+	if r.Role == RoleMaster {
+		if len(r.Addresses) == 0 {
+			if err := r.lookupMasters(ctx, cfg); err != nil {
+				return ResolvedConfig{}, err
+			}
+		}
+
+		if cfg.VerifyDiscoveryNames {
+			if err := r.verifyDiscoveryNames(ctx, cfg); err != nil {
+				return ResolvedConfig{}, err
+			}
+		}
+
+		if r.Token == "" {
+			if r.Token, err = t.GenerateToken(); err != nil {
+				return ResolvedConfig{}, fmt.Errorf("error generating bootstrap token: %v", err)
+			}
+		}
+	}
+
+	if err := r.Networking.checkNodeIPs(r.nodeIPs()); err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	joining := r.Role != RoleMaster
+	required := cfg.RequireCACert || (joining && !cfg.SkipCAVerification)
+	if r.CACertHash, err = caCertHash(cfg.CACert, required); err != nil {
+		if joining && !cfg.RequireCACert {
+			return ResolvedConfig{}, fmt.Errorf("%v, joining nodes verify the cluster CA unless skipping CA verification", err)
+		}
+		return ResolvedConfig{}, err
+	}
+	r.SkipCAVerification = joining && r.CACertHash == ""
+
+	return r, nil
+}
+
+// tokenOptions fills in the defaults of the bootstrap token options
+func tokenOptions(opts *t.Options) t.Options {
+	if opts == nil {
+		return t.Options{}.WithDefaults()
+	}
+	return opts.WithDefaults()
+}
+
+// lookupMasters finds the master addresses in DNS, and adds the service IP
+// to them. When the masters come from an SRV name, there are as many as it
+// has hosts
+func (r *ResolvedConfig) lookupMasters(ctx context.Context, cfg Config) error {
+	resolver := cfg.resolver()
+	ctx, cancel := cfg.lookupContext(ctx)
+	defer cancel()
+
+	var addresses []string
+	if cfg.MasterSRV != "" {
+		log.Info("Looking up masters from ", cfg.MasterSRV)
+		hosts, found, err := n.LookupSRV(ctx, resolver, cfg.MasterSRV)
+		if err != nil {
+			return err
+		}
+		log.Info("Found ", len(hosts), " masters: ", strings.Join(hosts, ", "))
+		r.NumberMasters, addresses = len(hosts), found
+	} else {
+		names, err := r.Naming.MasterNames(r.namingVars(), r.NumberMasters)
+		if err != nil {
+			return err
+		}
+		found, err := n.LookupHosts(ctx, resolver, names)
+		if err != nil {
+			return err
+		}
+		addresses = found
+	}
+
+	r.Addresses = append(addresses, r.SvcIP)
+	return nil
+}
+
+// verifyDiscoveryNames looks up the discovery names, and skips the ones that
+// don't resolve
+func (r *ResolvedConfig) verifyDiscoveryNames(ctx context.Context, cfg Config) error {
+	names, err := r.Naming.DiscoveryNames(r.namingVars())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cfg.lookupContext(ctx)
+	defer cancel()
+
+	_, err = n.LookupHosts(ctx, cfg.resolver(), names)
+	failed, ok := err.(n.LookupErrors)
+	if err != nil && !ok {
+		return err
+	}
+	for _, lookupErr := range failed {
+		log.Warn("Leaving discovery name out of the cert SANs: ", lookupErr)
+	}
+	r.SkippedDiscoveryNames = failed.Names()
+	return nil
+}
+
+// resolver returns the resolver DNS lookups use
+func (cfg Config) resolver() n.Resolver {
+	if cfg.Resolver == nil {
+		return n.DefaultResolver
+	}
+	return cfg.Resolver
+}
+
+// lookupContext limits how long DNS lookups can take, if there is a timeout
+func (cfg Config) lookupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cfg.LookupTimeout > 0 {
+		return context.WithTimeout(ctx, cfg.LookupTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// namingVars are what the naming templates of the cluster can use
+func (r ResolvedConfig) namingVars() naming.Vars {
+	return naming.Vars{Datacenter: r.Datacenter, Cluster: r.ClusterName, Domain: r.DomainName}
+}
+
+// nodeIPs are the addresses of this node and the masters, without the service
+// IP that is added to the master addresses looked up in DNS
+func (r ResolvedConfig) nodeIPs() []string {
+	ips := []string{r.IPAddress}
+	if r.IPAddress6 != "" {
+		ips = append(ips, r.IPAddress6)
+	}
+	for _, ip := range r.Addresses {
+		if ip != r.SvcIP {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// caCertHash computes the public key pin of the cluster CA. A missing CA is
+// only an error if it's required, as it may not have been distributed to this
+// node yet
+func caCertHash(path string, required bool) (string, error) {
+	if path == "" && !required {
+		return "", nil
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) && !required {
+		log.Warn("No CA certificate found at ", path, ", discovery won't verify the cluster CA")
+		return "", nil
+	}
+
+	cert, err := c.LoadCertificate(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading CA certificate: %v", err)
+	}
+
+	return c.CACertHash(cert), nil
+}
@@ -0,0 +1,163 @@
+package bootstrap
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+
+	f "github.com/apptio/kubeadm-bootstrap/pkg/facts"
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+)
+
+func init() {
+	// detected facts and translated args are logged as warnings
+	log.SetLevel(log.ErrorLevel)
+}
+
+// staticFacts is a fact provider answering from a map
+type staticFacts map[string]string
+
+func (s staticFacts) Name() string { return "static" }
+
+func (s staticFacts) Fact(name string) (string, bool, error) {
+	v, ok := s[name]
+	return v, ok, nil
+}
+
+// testConfig is a config that resolves without touching the node or DNS
+func testConfig(apiVersion, role string) Config {
+	return Config{
+		APIVersion:    apiVersion,
+		Role:          role,
+		Datacenter:    "dc1",
+		ClusterName:   "k1",
+		DomainName:    "example.com",
+		NodeName:      "node1",
+		AddressList:   []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		NumberMasters: 3,
+		Token:         "abcdef.0123456789abcdef",
+		Etcd:          EtcdConfig{Mode: "external", Count: 3},
+		Facts:         f.Chain{staticFacts{f.IPAddress: "192.0.2.10", f.IPAddress6: "2001:db8::10"}},
+
+		SkipCAVerification: true,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"valid master", func(cfg *Config) {}, ""},
+		{"valid worker", func(cfg *Config) { cfg.Role = RoleWorker }, ""},
+		{"valid control plane", func(cfg *Config) { cfg.Role = RoleControlPlane }, ""},
+		{"unknown API version", func(cfg *Config) { cfg.APIVersion = "v2" }, "unsupported kubeadm API version v2"},
+		{"unknown role", func(cfg *Config) { cfg.Role = "etcd" }, "unknown role etcd"},
+		{"control plane join on v1alpha1", func(cfg *Config) { cfg.APIVersion, cfg.Role = "v1alpha1", RoleControlPlane }, "joining control plane nodes is not supported"},
+		{"dual-stack on v1beta1", func(cfg *Config) {
+			cfg.APIVersion, cfg.Networking.PodSubnet = "v1beta1", "10.244.0.0/16,fd00:10:244::/56"
+		}, "dual-stack networking is not supported"},
+		{"components for a worker", func(cfg *Config) { cfg.Role, cfg.Components = RoleWorker, true }, "component configs can only be rendered for the bootstrap master"},
+		{"unknown output format", func(cfg *Config) { cfg.OutputFormat = "toml" }, "unknown output format toml"},
+		{"invalid kubernetes version", func(cfg *Config) { cfg.KubernetesVersion = "latest" }, "latest"},
+		{"no cluster name", func(cfg *Config) { cfg.ClusterName = "" }, "please specify a cluster name"},
+		{"worker without token", func(cfg *Config) { cfg.Role, cfg.Token = RoleWorker, "" }, "please specify the bootstrap token"},
+		{"invalid token", func(cfg *Config) { cfg.Token = "abc" }, "invalid bootstrap token"},
+		{"unknown etcd mode", func(cfg *Config) { cfg.Etcd.Mode = "stacked" }, "unknown etcd mode stacked"},
+		{"no etcd members", func(cfg *Config) { cfg.Etcd.Count = 0 }, "please specify at least one etcd member"},
+		{"invalid naming template", func(cfg *Config) { cfg.Naming.Master = "{{.Datacenter}}-master" }, "must use {{.Index}}"},
+	}
+
+	for _, test := range tests {
+		cfg := testConfig("v1beta2", RoleMaster)
+		cfg.OutputFormat = FormatJSON
+		test.modify(&cfg)
+
+		err := cfg.validate()
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r, err := Resolve(context.Background(), testConfig("v1beta2", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Role != RoleMaster || r.OutputFormat != FormatJSON {
+		t.Errorf("defaults not filled in: role %q, output format %q", r.Role, r.OutputFormat)
+	}
+	if r.SvcIP != "10.96.0.1" {
+		t.Errorf("got service IP %s, want 10.96.0.1", r.SvcIP)
+	}
+	if r.IPAddress != "192.0.2.10" || r.Sources[f.IPAddress] != "static" {
+		t.Errorf("got IP address %s from %q", r.IPAddress, r.Sources[f.IPAddress])
+	}
+	if r.SkipCAVerification {
+		t.Error("the bootstrap master doesn't skip CA verification")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"service IP mismatch", func(cfg *Config) { cfg.SvcIP = "10.96.0.10" }, "service IP 10.96.0.10 must be 10.96.0.1"},
+		{"service IP of another CIDR", func(cfg *Config) { cfg.SvcIP, cfg.Networking.ServiceSubnet = "10.96.0.1", "10.100.0.0/16" }, "service IP 10.96.0.1 must be 10.100.0.1"},
+		{"CIDR overlap", func(cfg *Config) { cfg.Networking.PodSubnet = "10.96.0.0/16" }, "overlaps service CIDR"},
+		{"master in the pod CIDR", func(cfg *Config) { cfg.Networking.PodSubnet = "10.0.0.0/16" }, "node address 10.0.0.1 is in the pod CIDR"},
+		{"no IP address", func(cfg *Config) { cfg.Facts = f.Chain{staticFacts{}} }, "unable to detect IP address"},
+		{"worker without CA", func(cfg *Config) { cfg.Role, cfg.SkipCAVerification = RoleWorker, false }, "joining nodes verify the cluster CA"},
+		{"unresolvable masters", func(cfg *Config) { cfg.AddressList, cfg.Resolver = nil, n.FakeResolver{} }, "error resolving dc1-k1master-1.example.com"},
+	}
+
+	for _, test := range tests {
+		cfg := testConfig("v1beta2", RoleMaster)
+		test.modify(&cfg)
+
+		_, err := Resolve(context.Background(), cfg)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestResolveLooksUpMasters(t *testing.T) {
+	cfg := testConfig("v1beta2", RoleMaster)
+	cfg.AddressList = nil
+	// the service IP is added to the masters looked up
+	cfg.Resolver = n.FakeResolver{Hosts: map[string][]string{
+		"dc1-k1master-1.example.com": {"10.0.0.1"},
+		"dc1-k1master-2.example.com": {"10.0.0.2"},
+		"dc1-k1master-3.example.com": {"10.0.0.3"},
+	}}
+
+	r, err := Resolve(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.96.0.1"}; !reflect.DeepEqual(r.Addresses, want) {
+		t.Errorf("got addresses %v, want %v", r.Addresses, want)
+	}
+}
+
+func TestResolveJoinSkipsCAVerification(t *testing.T) {
+	r, err := Resolve(context.Background(), testConfig("v1beta2", RoleWorker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.SkipCAVerification || r.CACertHash != "" {
+		t.Errorf("expected CA verification to be skipped, got hash %q", r.CACertHash)
+	}
+}
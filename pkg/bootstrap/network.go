@@ -0,0 +1,164 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// The CNI plugins with networking presets
+const (
+	CNICalico  = "calico"
+	CNIFlannel = "flannel"
+	CNICilium  = "cilium"
+)
+
+// NetworkConfig describes the cluster networks. Dual-stack clusters have an
+// IPv4 and an IPv6 CIDR, separated by a comma
+type NetworkConfig struct {
+	// PodSubnet is the pod CIDR. Defaults to the CNI preset's, or none
+	PodSubnet string
+	// ServiceSubnet is the service CIDR, defaults to 10.96.0.0/12
+	ServiceSubnet string
+	// DNSDomain is the cluster DNS domain, defaults to cluster.local
+	DNSDomain string
+	// CNI is the name of a networking preset, or empty for none
+	CNI string
+	// AllocateNodeCIDRs has the controller manager give each node a slice of
+	// the pod CIDR. It is set by the CNI preset
+	AllocateNodeCIDRs bool
+}
+
+// cniPreset is what a CNI plugin expects of the cluster networks
+type cniPreset struct {
+	podSubnet         string
+	allocateNodeCIDRs bool
+}
+
+// cniPresets are the pod CIDRs each CNI plugin's default manifests use.
+// Calico does its own IP address management, Flannel and Cilium (in
+// kubernetes IPAM mode) use the node CIDRs allocated by the controller manager
+var cniPresets = map[string]cniPreset{
+	CNICalico:  {podSubnet: "192.168.0.0/16"},
+	CNIFlannel: {podSubnet: "10.244.0.0/16", allocateNodeCIDRs: true},
+	CNICilium:  {podSubnet: "10.217.0.0/16", allocateNodeCIDRs: true},
+}
+
+// SupportedCNIs returns the names of the CNI presets
+func SupportedCNIs() []string {
+	var names []string
+	for name := range cniPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve fills in the defaults and the CNI preset, and checks the networks
+// don't overlap each other
+func (nc NetworkConfig) resolve() (NetworkConfig, error) {
+	if nc.CNI != "" {
+		preset, ok := cniPresets[nc.CNI]
+		if !ok {
+			return nc, fmt.Errorf("unknown CNI %s, must be one of: %s", nc.CNI, strings.Join(SupportedCNIs(), ", "))
+		}
+		if nc.PodSubnet == "" {
+			nc.PodSubnet = preset.podSubnet
+		}
+		nc.AllocateNodeCIDRs = preset.allocateNodeCIDRs
+	}
+	if nc.ServiceSubnet == "" {
+		nc.ServiceSubnet = "10.96.0.0/12"
+	}
+	if nc.DNSDomain == "" {
+		nc.DNSDomain = "cluster.local"
+	}
+
+	services, err := parseCIDRs("service", nc.ServiceSubnet)
+	if err != nil {
+		return nc, err
+	}
+	pods, err := parseCIDRs("pod", nc.PodSubnet)
+	if err != nil {
+		return nc, err
+	}
+	for _, pod := range pods {
+		for _, service := range services {
+			if overlaps(pod, service) {
+				return nc, fmt.Errorf("pod CIDR %s overlaps service CIDR %s", pod, service)
+			}
+		}
+	}
+
+	return nc, nil
+}
+
+// dualStack returns true if either network has both an IPv4 and an IPv6 CIDR
+func (nc NetworkConfig) dualStack() bool {
+	return strings.Contains(nc.PodSubnet, ",") || strings.Contains(nc.ServiceSubnet, ",")
+}
+
+// serviceIP returns the first address of the service CIDR, which kubernetes
+// gives to the API server's service. Dual-stack clusters use the first CIDR
+func (nc NetworkConfig) serviceIP() string {
+	services, _ := parseCIDRs("service", nc.ServiceSubnet)
+	service := services[0]
+	ip := make(net.IP, len(service.IP))
+	copy(ip, service.IP)
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// checkNodeIPs checks that node addresses are outside the pod and service
+// CIDRs
+func (nc NetworkConfig) checkNodeIPs(ips []string) error {
+	for _, network := range []struct{ name, cidrs string }{{"service", nc.ServiceSubnet}, {"pod", nc.PodSubnet}} {
+		cidrs, _ := parseCIDRs(network.name, network.cidrs)
+		for _, cidr := range cidrs {
+			for _, ip := range ips {
+				if parsed := net.ParseIP(ip); parsed != nil && cidr.Contains(parsed) {
+					return fmt.Errorf("node address %s is in the %s CIDR %s", ip, network.name, cidr)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseCIDRs parses a single CIDR, or an IPv4 and an IPv6 CIDR separated by
+// a comma
+func parseCIDRs(name, cidrs string) ([]*net.IPNet, error) {
+	if cidrs == "" {
+		return nil, nil
+	}
+
+	var parsed []*net.IPNet
+	for _, cidr := range strings.Split(cidrs, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s CIDR %s", name, cidr)
+		}
+		parsed = append(parsed, ipNet)
+	}
+
+	if len(parsed) > 2 || (len(parsed) == 2 && isIPv6(parsed[0].IP) == isIPv6(parsed[1].IP)) {
+		return nil, fmt.Errorf("invalid %s CIDRs %s, dual-stack needs one IPv4 and one IPv6 CIDR", name, cidrs)
+	}
+	return parsed, nil
+}
+
+// isIPv6 returns true for IPv6 addresses that aren't IPv4 mapped
+func isIPv6(ip net.IP) bool {
+	return ip.To4() == nil
+}
+
+// overlaps returns true if either network contains the other
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
@@ -0,0 +1,85 @@
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetworkResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		in   NetworkConfig
+		want NetworkConfig
+	}{
+		{
+			"defaults",
+			NetworkConfig{},
+			NetworkConfig{ServiceSubnet: "10.96.0.0/12", DNSDomain: "cluster.local"},
+		},
+		{
+			"CNI preset",
+			NetworkConfig{CNI: CNIFlannel},
+			NetworkConfig{PodSubnet: "10.244.0.0/16", ServiceSubnet: "10.96.0.0/12", DNSDomain: "cluster.local", CNI: CNIFlannel, AllocateNodeCIDRs: true},
+		},
+		{
+			"CNI preset with pod CIDR",
+			NetworkConfig{CNI: CNICalico, PodSubnet: "172.16.0.0/16"},
+			NetworkConfig{PodSubnet: "172.16.0.0/16", ServiceSubnet: "10.96.0.0/12", DNSDomain: "cluster.local", CNI: CNICalico},
+		},
+		{
+			"dual-stack",
+			NetworkConfig{PodSubnet: "10.244.0.0/16,fd00:10:244::/56", ServiceSubnet: "10.96.0.0/12,fd00:10:96::/112"},
+			NetworkConfig{PodSubnet: "10.244.0.0/16,fd00:10:244::/56", ServiceSubnet: "10.96.0.0/12,fd00:10:96::/112", DNSDomain: "cluster.local"},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := test.in.resolve()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNetworkResolveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   NetworkConfig
+		err  string
+	}{
+		{"unknown CNI", NetworkConfig{CNI: "weave"}, "unknown CNI weave"},
+		{"invalid service CIDR", NetworkConfig{ServiceSubnet: "10.96.0.0"}, "invalid service CIDR 10.96.0.0"},
+		{"invalid pod CIDR", NetworkConfig{PodSubnet: "pods"}, "invalid pod CIDR pods"},
+		{"pod CIDR in service CIDR", NetworkConfig{PodSubnet: "10.100.0.0/16"}, "pod CIDR 10.100.0.0/16 overlaps service CIDR 10.96.0.0/12"},
+		{"service CIDR in pod CIDR", NetworkConfig{PodSubnet: "10.0.0.0/8"}, "pod CIDR 10.0.0.0/8 overlaps service CIDR 10.96.0.0/12"},
+		{"IPv6 overlap", NetworkConfig{PodSubnet: "10.244.0.0/16,fd00::/8", ServiceSubnet: "10.96.0.0/12,fd00:10:96::/112"}, "pod CIDR fd00::/8 overlaps"},
+		{"two IPv4 CIDRs", NetworkConfig{PodSubnet: "10.244.0.0/16,10.245.0.0/16"}, "dual-stack needs one IPv4 and one IPv6 CIDR"},
+	}
+
+	for _, test := range tests {
+		_, err := test.in.resolve()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestServiceIP(t *testing.T) {
+	tests := map[string]string{
+		"10.96.0.0/12":                  "10.96.0.1",
+		"10.100.255.0/24":               "10.100.255.1",
+		"fd00:10:96::/112":              "fd00:10:96::1",
+		"10.96.0.0/12,fd00:10:96::/112": "10.96.0.1",
+		"fd00:10:96::/112,10.96.0.0/12": "fd00:10:96::1",
+	}
+
+	for subnet, want := range tests {
+		if got := (NetworkConfig{ServiceSubnet: subnet}).serviceIP(); got != want {
+			t.Errorf("%s: got %s, want %s", subnet, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The formats a config can be written in
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// document is one document of a rendered config. raw is the JSON evaluated by
+// jsonnet, value is set instead for documents parsed from a YAML stream. whole
+// is set if the template evaluated to the document itself, so raw is
+// formatted as a top level value
+type document struct {
+	raw   json.RawMessage
+	value interface{}
+	whole bool
+}
+
+// splitDocuments splits evaluated jsonnet into documents. A template can
+// return a single object, an array of documents, or a YAML stream string such
+// as the output of std.manifestYamlStream
+func splitDocuments(out string) ([]document, error) {
+	trimmed := strings.TrimSpace(out)
+
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var raws []json.RawMessage
+		if err := json.Unmarshal([]byte(out), &raws); err != nil {
+			return nil, err
+		}
+		var docs []document
+		for _, raw := range raws {
+			docs = append(docs, document{raw: raw})
+		}
+		return docs, nil
+
+	case strings.HasPrefix(trimmed, `"`):
+		var stream string
+		if err := json.Unmarshal([]byte(out), &stream); err != nil {
+			return nil, err
+		}
+		var docs []document
+		dec := yaml.NewDecoder(strings.NewReader(stream))
+		for {
+			var value interface{}
+			err := dec.Decode(&value)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error parsing YAML stream from template: %v", err)
+			}
+			if value == nil {
+				continue
+			}
+			if value, err = jsonValue(value); err != nil {
+				return nil, err
+			}
+			docs = append(docs, document{value: value})
+		}
+		return docs, nil
+
+	default:
+		return []document{{raw: json.RawMessage(out), whole: true}}, nil
+	}
+}
+
+// formatDocuments writes documents as JSON or YAML, separated by the ---
+// kubeadm expects for multi-document configs
+func formatDocuments(docs []document, format string) (string, error) {
+	// a single JSON document is written as jsonnet formatted it, unless it was
+	// nested in an array and indented as such
+	if format == FormatJSON && len(docs) == 1 && docs[0].whole {
+		return string(docs[0].raw), nil
+	}
+
+	var stream []string
+	for _, doc := range docs {
+		var out string
+		var err error
+		switch format {
+		case FormatJSON:
+			out, err = doc.json()
+		case FormatYAML:
+			out, err = doc.yaml()
+		default:
+			err = fmt.Errorf("unknown output format %s, must be one of: %s, %s", format, FormatJSON, FormatYAML)
+		}
+		if err != nil {
+			return "", err
+		}
+		stream = append(stream, out)
+	}
+
+	return strings.Join(stream, "---\n"), nil
+}
+
+// json indents a document the way jsonnet does
+func (d document) json() (string, error) {
+	raw := d.raw
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(d.value); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "   "); err != nil {
+		return "", err
+	}
+	return buf.String() + "\n", nil
+}
+
+// yaml writes a document with its keys sorted
+func (d document) yaml() (string, error) {
+	value, err := d.decode()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(yamlValue(value))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// decode returns the value of a document, keeping JSON numbers as they were
+// written
+func (d document) decode() (interface{}, error) {
+	if d.value != nil {
+		return d.value, nil
+	}
+
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(d.raw))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// jsonValue converts a value decoded from YAML into one encoding/json can
+// marshal, as YAML maps can have keys of any type
+func jsonValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("YAML stream from template has a non string key %v", key)
+			}
+			var err error
+			if m[k], err = jsonValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			var err error
+			if v[i], err = jsonValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// yamlValue converts JSON numbers back to integers where possible, so they
+// aren't written as quoted strings or in exponent form
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = yamlValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlValue(item)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
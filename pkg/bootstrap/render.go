@@ -0,0 +1,387 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GeertJohan/go.rice"
+	log "github.com/Sirupsen/logrus"
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+
+	"github.com/apptio/kubeadm-bootstrap/pkg/compat"
+	"github.com/apptio/kubeadm-bootstrap/pkg/naming"
+)
+
+// entrypoints are the jsonnet snippets that render each kind of config from
+// the embedded library
+type entrypoints struct {
+	// master renders the bootstrap master's config
+	master string
+	// join renders the config for additional control plane and worker nodes
+	join string
+	// controlPlaneJoin is false when kubeadm can't join additional masters
+	// using this API version
+	controlPlaneJoin bool
+	// dualStack is false when kubeadm can't take dual-stack networks using
+	// this API version
+	dualStack bool
+}
+
+// apiVersions maps each supported kubeadm config API version to its templates
+var apiVersions = map[string]entrypoints{
+	"v1alpha1": {
+		master: `import "kubeadm.libsonnet"`,
+		join:   `import "kubeadm-join.libsonnet"`,
+	},
+	"v1beta1": {
+		master:           `(import "kubeadm-v1beta1.libsonnet").master`,
+		join:             `(import "kubeadm-v1beta1.libsonnet").join`,
+		controlPlaneJoin: true,
+	},
+	"v1beta2": {
+		master:           `(import "kubeadm-v1beta2.libsonnet").master`,
+		join:             `(import "kubeadm-v1beta2.libsonnet").join`,
+		controlPlaneJoin: true,
+		dualStack:        true,
+	},
+	"v1beta3": {
+		master:           `(import "kubeadm-v1beta3.libsonnet").master`,
+		join:             `(import "kubeadm-v1beta3.libsonnet").join`,
+		controlPlaneJoin: true,
+		dualStack:        true,
+	},
+}
+
+// SupportedAPIVersions returns the kubeadm config API versions that can be
+// rendered, sorted
+func SupportedAPIVersions() []string {
+	var versions []string
+	for v := range apiVersions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// libraryPrefix is the import path of the embedded template library, so
+// templates on disk can always reach it even if a search path shadows it
+const libraryPrefix = "kubeadm-bootstrap/"
+
+// importer resolves jsonnet imports relative to the importing file, then in
+// the library search paths, last first, then in the embedded library. Files in
+// the embedded library only import each other
+type importer struct {
+	box   *rice.Box
+	jpath []string
+	// sources are the contents of every imported file
+	sources []string
+}
+
+func (i *importer) Import(dir, importedPath string) (*jsonnet.ImportedData, error) {
+	if dir == libraryPrefix && !strings.HasPrefix(importedPath, libraryPrefix) {
+		importedPath = libraryPrefix + importedPath
+	}
+	if strings.HasPrefix(importedPath, libraryPrefix) {
+		return i.library(importedPath)
+	}
+
+	// the built in entrypoints have no directory of their own
+	var dirs []string
+	if dir != "" {
+		dirs = append(dirs, dir)
+	}
+	for j := len(i.jpath) - 1; j >= 0; j-- {
+		dirs = append(dirs, i.jpath[j])
+	}
+
+	for _, d := range dirs {
+		p := importedPath
+		if !path.IsAbs(p) {
+			p = path.Join(d, p)
+		}
+		content, err := ioutil.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open import %q: %v", importedPath, err)
+		}
+		i.sources = append(i.sources, string(content))
+		return &jsonnet.ImportedData{Content: string(content), FoundHere: p}, nil
+	}
+
+	if data, err := i.library(libraryPrefix + importedPath); err == nil {
+		return data, nil
+	}
+	return nil, fmt.Errorf("couldn't open import %q: not found locally, in the library search paths or the embedded library", importedPath)
+}
+
+// library imports a file from the embedded library
+func (i *importer) library(importedPath string) (*jsonnet.ImportedData, error) {
+	content, err := i.box.String(strings.TrimPrefix(importedPath, libraryPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open import %q: not found in the embedded library", importedPath)
+	}
+	i.sources = append(i.sources, content)
+	return &jsonnet.ImportedData{Content: content, FoundHere: importedPath}, nil
+}
+
+// namingFunctions render the naming templates for the template library, so
+// names are the same as in lookups. std.native("name")(template, vars)
+// renders one template, std.native("endpointName")(naming, vars) and
+// std.native("discoveryNames")(naming, vars) the names of the naming ext var
+var namingFunctions = []*jsonnet.NativeFunction{
+	{
+		Name:   "name",
+		Params: ast.Identifiers{"template", "vars"},
+		Func: func(args []interface{}) (interface{}, error) {
+			tmpl, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("naming template must be a string")
+			}
+			var vars naming.Vars
+			if err := decodeArg(args[1], &vars); err != nil {
+				return nil, fmt.Errorf("invalid naming vars: %v", err)
+			}
+			return naming.Name(tmpl, vars)
+		},
+	},
+	{
+		Name:   "endpointName",
+		Params: ast.Identifiers{"naming", "vars"},
+		Func: func(args []interface{}) (interface{}, error) {
+			conventions, vars, err := decodeNaming(args)
+			if err != nil {
+				return nil, err
+			}
+			return conventions.EndpointName(vars)
+		},
+	},
+	{
+		Name:   "discoveryNames",
+		Params: ast.Identifiers{"naming", "vars"},
+		Func: func(args []interface{}) (interface{}, error) {
+			conventions, vars, err := decodeNaming(args)
+			if err != nil {
+				return nil, err
+			}
+			names, err := conventions.DiscoveryNames(vars)
+			if err != nil {
+				return nil, err
+			}
+			// native functions return JSON values
+			values := []interface{}{}
+			for _, name := range names {
+				values = append(values, name)
+			}
+			return values, nil
+		},
+	},
+}
+
+// decodeNaming decodes the naming conventions and vars args of a native
+// function
+func decodeNaming(args []interface{}) (naming.Conventions, naming.Vars, error) {
+	var conventions naming.Conventions
+	var vars naming.Vars
+	if err := decodeArg(args[0], &conventions); err != nil {
+		return conventions, vars, fmt.Errorf("invalid naming conventions: %v", err)
+	}
+	if err := decodeArg(args[1], &vars); err != nil {
+		return conventions, vars, fmt.Errorf("invalid naming vars: %v", err)
+	}
+	return conventions, vars, nil
+}
+
+// decodeArg decodes a native function arg into a struct. Args are decoded
+// from JSON, so numbers are float64s
+func decodeArg(arg interface{}, v interface{}) error {
+	fields, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(fields, v)
+}
+
+// Render evaluates the template for a resolved config's API version and role,
+// or the on-disk template if one is set, and returns the kubeadm config
+func Render(r ResolvedConfig) ([]byte, error) {
+	tmpl, ok := apiVersions[r.APIVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported kubeadm API version %s", r.APIVersion)
+	}
+
+	filename, snippet := "<builtin>", tmpl.master
+	if r.Role != RoleMaster {
+		snippet = tmpl.join
+	}
+
+	if r.Template != "" {
+		// an absolute filename lets the template import files next to it
+		var err error
+		if filename, err = filepath.Abs(r.Template); err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template: %v", err)
+		}
+		snippet = string(content)
+	}
+
+	// read static assets
+	templateBox, err := rice.FindBox("../../lib")
+	if err != nil {
+		return nil, err
+	}
+
+	extraArgs := r.Etcd.ExtraArgs
+	if extraArgs == nil {
+		extraArgs = map[string]string{}
+	}
+	etcdExtraArgs, err := json.Marshal(extraArgs)
+	if err != nil {
+		return nil, err
+	}
+	namingTemplates, err := json.Marshal(r.Naming)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a jsonnet vm
+	vm := jsonnet.MakeVM()
+	imports := &importer{box: templateBox, jpath: r.JPath}
+	vm.Importer(imports)
+	for _, f := range namingFunctions {
+		vm.NativeFunction(f)
+	}
+
+	// populate jsonnet extvars
+	extVars := map[string]string{
+		"datacenter":     r.Datacenter,
+		"clustername":    r.ClusterName,
+		"domainname":     r.DomainName,
+		"nodename":       r.NodeName,
+		"cloudprovider":  r.CloudProvider,
+		"ipaddress":      r.IPAddress,
+		"ipaddress6":     r.IPAddress6,
+		"addresslist":    strings.Join(r.Addresses, ","),
+		"token":          r.Token,
+		"number_masters": strconv.Itoa(r.NumberMasters),
+		"role":           r.Role,
+		"cacerthash":     r.CACertHash,
+		"skip_ca_verify": strconv.FormatBool(r.SkipCAVerification),
+		"etcd_mode":      r.Etcd.Mode,
+		"etcd_count":     strconv.Itoa(r.Etcd.Count),
+		"etcd_endpoints": strings.Join(r.Etcd.Endpoints, ","),
+		"etcd_cafile":    r.Etcd.CAFile,
+		"etcd_certfile":  r.Etcd.CertFile,
+		"etcd_keyfile":   r.Etcd.KeyFile,
+		"etcd_datadir":   r.Etcd.DataDir,
+
+		"skipped_discovery_names": strings.Join(r.SkippedDiscoveryNames, ","),
+
+		"token_ttl":         tokenTTL(r.TokenOptions.TTL),
+		"token_usages":      strings.Join(r.TokenOptions.Usages, ","),
+		"token_groups":      strings.Join(r.TokenOptions.Groups, ","),
+		"token_description": r.TokenOptions.Description,
+
+		"kubernetes_version": r.KubernetesVersion,
+		"components":         strconv.FormatBool(r.Components),
+
+		"pod_subnet":          r.Networking.PodSubnet,
+		"service_subnet":      r.Networking.ServiceSubnet,
+		"dns_domain":          r.Networking.DNSDomain,
+		"allocate_node_cidrs": strconv.FormatBool(r.Networking.AllocateNodeCIDRs),
+		"dual_stack":          strconv.FormatBool(r.Networking.dualStack()),
+	}
+	extCode := map[string]string{
+		"etcd_extra_args": string(etcdExtraArgs),
+		"naming":          string(namingTemplates),
+	}
+	for name, value := range extVars {
+		vm.ExtVar(name, value)
+	}
+	for name, value := range extCode {
+		vm.ExtCode(name, value)
+	}
+
+	if err := r.Vars.set(vm, extVars, extCode); err != nil {
+		return nil, err
+	}
+
+	// evaluate jsonnet snippet
+	out, err := evaluate(vm, filename, snippet, r.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Vars.reportUnused(filename, snippet, imports.sources)
+
+	return []byte(out), nil
+}
+
+// tokenTTL formats a token TTL, writing a token that never expires as "0"
+// as the templates always have
+func tokenTTL(ttl time.Duration) string {
+	if ttl == 0 {
+		return "0"
+	}
+	return ttl.String()
+}
+
+// evaluate runs a jsonnet snippet and returns the kubeadm config in the
+// given format
+func evaluate(vm *jsonnet.VM, filename, snippet, format string) (string, error) {
+	out, err := vm.EvaluateSnippet(filename, snippet)
+	if err != nil {
+		return "", err
+	}
+
+	docs, err := splitDocuments(out)
+	if err != nil {
+		return "", err
+	}
+
+	if err := translate(docs); err != nil {
+		return "", err
+	}
+
+	return formatDocuments(docs, format)
+}
+
+// translate rewrites the control plane args of each document for the
+// kubernetes version it targets
+func translate(docs []document) error {
+	for i, doc := range docs {
+		value, err := doc.decode()
+		if err != nil {
+			return err
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		warnings, err := compat.Translate(m)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			log.Warn(warning)
+		}
+		if len(warnings) > 0 {
+			docs[i] = document{value: m}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package bootstrap
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	f "github.com/apptio/kubeadm-bootstrap/pkg/facts"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestRenderGolden renders the configs of every API version and role, and
+// compares them with testdata/<version>-<role>.json. Run with -update after
+// changing the templates
+func TestRenderGolden(t *testing.T) {
+	for _, version := range SupportedAPIVersions() {
+		roles := []string{RoleMaster, RoleWorker}
+		if apiVersions[version].controlPlaneJoin {
+			roles = append(roles, RoleControlPlane)
+		}
+
+		for _, role := range roles {
+			name := version + "-" + role
+			r, err := Resolve(context.Background(), testConfig(version, role))
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			out, err := Render(r)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+
+			checkGolden(t, name, out)
+		}
+	}
+}
+
+// TestRenderComponents renders the component configs with the pod CIDR and
+// cloud provider they take, v1alpha1 for the first release with both fields
+func TestRenderComponents(t *testing.T) {
+	for version, kubernetesVersion := range map[string]string{"v1alpha1": "v1.10.13", "v1beta2": ""} {
+		cfg := testConfig(version, RoleMaster)
+		cfg.Components = true
+		cfg.KubernetesVersion = kubernetesVersion
+		cfg.Facts = f.Chain{staticFacts{f.IPAddress: "192.0.2.10", f.CloudProvider: "aws"}}
+		cfg.Networking.PodSubnet = "10.244.0.0/16"
+
+		name := version + "-components"
+		r, err := Resolve(context.Background(), cfg)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		out, err := Render(r)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		checkGolden(t, name, out)
+	}
+}
+
+// checkGolden compares a rendered config with testdata/<name>.json, or
+// updates it
+func checkGolden(t *testing.T, name string, out []byte) {
+	golden := filepath.Join("testdata", name+".json")
+	if *update {
+		if err := ioutil.WriteFile(golden, out, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	if string(out) != string(want) {
+		t.Errorf("%s: rendered config differs from %s:\n%s", name, golden, out)
+	}
+}
+
+func TestRenderYAML(t *testing.T) {
+	cfg := testConfig("v1beta2", RoleMaster)
+	cfg.OutputFormat = FormatYAML
+	r, err := Resolve(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Render(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"kind: InitConfiguration\n", "---\n", "kind: ClusterConfiguration\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in YAML output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"github.com/GeertJohan/go.rice/embedded"
+	"time"
+)
+
+func init() {
+
+	// define files
+	file2 := &embedded.EmbeddedFile{
+		Filename:    "common.libsonnet",
+		FileModTime: time.Unix(1792296920, 0),
+		Content:     string("// Inputs and derived values shared by every kubeadm API version.\n// Everything here is hidden so each version library decides what to render.\n{\n\n    string_to_int(s)::\n        local char_to_int(c) = std.codepoint(c) - std.codepoint(\"0\");\n        local digits = std.map(char_to_int, std.stringChars(s));\n        std.foldr(function(x, y) x + y,\n                  std.makeArray(std.length(digits),\n                                function(x) digits[std.length(digits) - x - 1] * std.pow(10, x)),\n                  0),\n\n    // splitList splits a comma separated list, which may be empty\n    splitList(s):: if s == \"\" then [] else std.split(s, \",\"),\n\n    // dedupe removes repeated items from a list, keeping the first of each\n    dedupe(list):: std.foldl(function(seen, x) if std.setMember(x, std.set(seen)) then seen else seen + [x], list, []),\n\n    // hostPort joins a host and a port, bracketing IPv6 addresses\n    hostPort(host, port)::\n        if std.length(std.split(host, \":\")) > 1 then\n            \"[\" + host + \"]:\" + std.toString(port)\n        else\n            host + \":\" + std.toString(port),\n\n\n    // Required arguments for this template\n    k8sVersion:: \"v1.8.4\",\n    // targetVersion is the kubernetes release to configure, the API\n    // version's default unless one is given\n    targetVersion:: if std.extVar(\"kubernetes_version\") != \"\" then std.extVar(\"kubernetes_version\") else $.k8sVersion,\n    clusterName:: std.extVar(\"clustername\"),\n    addressList:: std.split(std.extVar(\"addresslist\"), \",\"),\n\n    datacenterName:: std.extVar(\"datacenter\"),\n\n    domainName:: std.extVar(\"domainname\"),\n\n    nodeName:: std.extVar(\"nodename\"),\n\n    cloudProvider:: std.extVar(\"cloudprovider\"),\n\n    ipAddress:: std.extVar(\"ipaddress\"),\n    // only set for dual-stack clusters\n    ipAddress6:: std.extVar(\"ipaddress6\"),\n\n    token:: std.extVar(\"token\"),\n    tokenTTL:: std.extVar(\"token_ttl\"),\n    tokenUsages:: $.splitList(std.extVar(\"token_usages\")),\n    tokenGroups:: $.splitList(std.extVar(\"token_groups\")),\n    tokenDescription:: std.extVar(\"token_description\"),\n\n    numberMasters:: std.extVar(\"number_masters\"),\n\n    // Go text/template strings naming the masters, etcd members and control\n    // plane, rendered by the name native function so they match the lookups\n    naming:: std.extVar(\"naming\"),\n    namingVars(index=0):: {\n        Datacenter: $.datacenterName,\n        Cluster: $.clusterName,\n        Domain: $.domainName,\n        Index: index,\n    },\n    nameOf(template, index=0):: std.native(\"name\")(template, $.namingVars(index)),\n\n    // discovery names that were verified and didn't resolve\n    skippedDiscoveryNames:: std.set($.splitList(std.extVar(\"skipped_discovery_names\"))),\n\n    // public key pin of the cluster CA, empty if the CA wasn't available\n    caCertHash:: std.extVar(\"cacerthash\"),\n    caCertHashes:: if $.caCertHash == \"\" then [] else [$.caCertHash],\n    // joining nodes only skip verifying the cluster CA if asked to\n    unsafeSkipCAVerification:: std.extVar(\"skip_ca_verify\") == \"true\",\n\n    // only set when rendering a join config: control-plane or worker\n    role:: std.extVar(\"role\"),\n\n    apiServerExtraArgs:: {\n        \"etcd-prefix\": $.datacenterName + \"-\" + $.clusterName,\n        profiling: \"false\",\n        //\"admission-control\": \"Initializers,NamespaceLifecycle,LimitRanger,ServiceAccount,PersistentVolumeLabel,DefaultStorageClass,DefaultTolerationSeconds,NodeRestriction,ResourceQuota,AlwaysPullImages,DenyEscalatingExec,SecurityContextDeny,PodSecurityPolicy\",\n        \"audit-log-path\": \"-\",\n        \"audit-log-maxage\": \"30\",\n        \"audit-log-maxbackup\": \"10\",\n        \"audit-log-maxsize\": \"100\",\n        \"service-account-lookup\": \"true\",\n        \"repair-malformed-updates\": \"false\",\n        \"apiserver-count\": $.numberMasters,\n        [if $.cloudProvider != \"\" then \"cloud-provider\"]: $.cloudProvider,\n        \"advertise-address\": $.ipAddress,\n        \"request-timeout\": \"300s\",\n        \"admission-control\": \"Initializers,NamespaceLifecycle,LimitRanger,ServiceAccount,PersistentVolumeLabel,DefaultStorageClass,DefaultTolerationSeconds,NodeRestriction,ResourceQuota,AlwaysPullImages,DenyEscalatingExec,SecurityContextDeny\",\n    },\n\n    controllerManagerExtraArgs:: {\n        profiling: \"false\",\n        \"terminated-pod-gc-threshold\": \"10\",\n        [if $.cloudProvider != \"\" then \"cloud-provider\"]: $.cloudProvider,\n        \"address\": \"0.0.0.0\",\n        [if $.allocateNodeCIDRs then \"allocate-node-cidrs\"]: \"true\",\n        [if $.allocateNodeCIDRs then \"cluster-cidr\"]: $.podSubnet,\n    },\n\n    schedulerExtraArgs:: {\n        profiling: \"false\",\n        \"address\": \"0.0.0.0\",\n    },\n\n    // cluster networks. the pod subnet is empty unless it was given or a CNI\n    // preset was chosen\n    podSubnet:: std.extVar(\"pod_subnet\"),\n    serviceSubnet:: std.extVar(\"service_subnet\"),\n    dnsDomain:: std.extVar(\"dns_domain\"),\n    allocateNodeCIDRs:: std.extVar(\"allocate_node_cidrs\") == \"true\",\n    // dual-stack subnets are an IPv4 and an IPv6 CIDR separated by a comma\n    dualStack:: std.extVar(\"dual_stack\") == \"true\",\n\n    networking:: {\n        serviceSubnet: $.serviceSubnet,\n        [if $.podSubnet != \"\" then \"podSubnet\"]: $.podSubnet,\n        dnsDomain: $.dnsDomain,\n    },\n\n    // external etcd uses etcdCount members named after the cluster unless\n    // endpoints are given explicitly. local etcd is stacked on the masters.\n    etcdMode:: std.extVar(\"etcd_mode\"),\n    externalEtcd:: $.etcdMode == \"external\",\n    localEtcd:: $.etcdMode == \"local\",\n\n    etcdCount:: $.string_to_int(std.extVar(\"etcd_count\")),\n\n    etcdEndpoints::\n        if std.extVar(\"etcd_endpoints\") != \"\" then\n            std.split(std.extVar(\"etcd_endpoints\"), \",\")\n        else\n            std.makeArray($.etcdCount, function(count) \"https://\" + $.hostPort($.nameOf($.naming.etcd, count + 1), 2379)),\n\n    etcdCAFile:: std.extVar(\"etcd_cafile\"),\n    etcdCertFile:: std.extVar(\"etcd_certfile\"),\n    etcdKeyFile:: std.extVar(\"etcd_keyfile\"),\n\n    etcdDataDir:: std.extVar(\"etcd_datadir\"),\n    etcdExtraArgs:: std.extVar(\"etcd_extra_args\"),\n    etcdServerCertSANs:: std.flattenArrays([$.apiServerNames, $.apiServerIPs]),\n\n    apiServerIPs:: $.addressList,\n\n    apiServerNames:: std.makeArray($.string_to_int($.numberMasters), function(count) $.nameOf($.naming.master, count + 1)),\n\n    apiServerDiscoveryNames:: [\n        name\n        for name in std.native(\"discoveryNames\")($.naming, $.namingVars())\n        if !std.setMember(name, $.skippedDiscoveryNames)\n    ],\n\n    // the name other nodes use to reach the control plane\n    apiServerEndpointName:: std.native(\"endpointName\")($.naming, $.namingVars()),\n    apiServerPort:: 6443,\n    apiServerEndpoint:: $.hostPort($.apiServerEndpointName, $.apiServerPort),\n\n    // the endpoint is always in the SANs, so nodes can join through it\n    apiServerCertSANs:: $.dedupe(std.flattenArrays([\n        $.apiServerNames,\n        $.apiServerIPs,\n        $.apiServerDiscoveryNames,\n        [$.apiServerEndpointName],\n    ])),\n\n}\n"),
+	}
+	file3 := &embedded.EmbeddedFile{
+		Filename:    "components.libsonnet",
+		FileModTime: time.Unix(1792297987, 0),
+		Content:     string("// KubeletConfiguration and KubeProxyConfiguration for the nodes of the\n// cluster, hardened to the CIS benchmark. Mixed into each kubeadm API version\n// library after common.libsonnet, and only rendered if components is set.\n{\n\n    components:: std.extVar(\"components\") == \"true\",\n\n    // the kubelet takes the cloud provider as a flag, KubeletConfiguration\n    // has no field for it\n    kubeletExtraArgs:: {\n        [if $.cloudProvider != \"\" then \"cloud-provider\"]: $.cloudProvider,\n    },\n\n    // clusterDNS is left for kubeadm to fill in from the service subnet\n    kubeletConfigurationSpec:: {\n        authentication: {\n            anonymous: {\n                enabled: false,\n            },\n            webhook: {\n                enabled: true,\n            },\n            x509: {\n                clientCAFile: \"/etc/kubernetes/pki/ca.crt\",\n            },\n        },\n        authorization: {\n            mode: \"Webhook\",\n        },\n        clusterDomain: $.dnsDomain,\n        readOnlyPort: 0,\n        protectKernelDefaults: true,\n        makeIPTablesUtilChains: true,\n        streamingConnectionIdleTimeout: \"5m0s\",\n        rotateCertificates: true,\n        serverTLSBootstrap: true,\n        tlsCipherSuites: [\n            \"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256\",\n            \"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256\",\n            \"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384\",\n            \"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384\",\n            \"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305\",\n            \"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305\",\n        ],\n    },\n\n    kubeProxyConfigurationSpec:: {\n        bindAddress: \"0.0.0.0\",\n        [if $.podSubnet != \"\" then \"clusterCIDR\"]: $.podSubnet,\n        healthzBindAddress: \"0.0.0.0:10256\",\n        metricsBindAddress: \"127.0.0.1:10249\",\n        mode: \"iptables\",\n    },\n\n    kubeletComponentConfig:: {\n        apiVersion: \"kubelet.config.k8s.io/v1beta1\",\n        kind: \"KubeletConfiguration\",\n    } + $.kubeletConfigurationSpec,\n\n    kubeProxyComponentConfig:: {\n        apiVersion: \"kubeproxy.config.k8s.io/v1alpha1\",\n        kind: \"KubeProxyConfiguration\",\n    } + $.kubeProxyConfigurationSpec,\n\n}\n"),
+	}
+	file4 := &embedded.EmbeddedFile{
+		Filename:    "kubeadm-join.libsonnet",
+		FileModTime: time.Unix(1792296920, 0),
+		Content:     string("// kubeadm.k8s.io/v1alpha1 NodeConfiguration for workers (kubeadm 1.8 - 1.10)\n(import \"common.libsonnet\") + {\n\n    apiVersion: \"kubeadm.k8s.io/v1alpha1\",\n    kind: \"NodeConfiguration\",\n    nodeName::: super.nodeName,\n    token::: super.token,\n    discoveryTokenAPIServers: [\n        $.apiServerEndpoint,\n    ],\n    discoveryTokenCACertHashes: $.caCertHashes,\n    discoveryTokenUnsafeSkipCAVerification: $.unsafeSkipCAVerification,\n\n}\n"),
+	}
+	file5 := &embedded.EmbeddedFile{
+		Filename:    "kubeadm-v1beta1.libsonnet",
+		FileModTime: time.Unix(1792297987, 0),
+		Content:     string("// kubeadm.k8s.io/v1beta1 (kubeadm 1.13 - 1.14)\n// The master config is split into InitConfiguration and ClusterConfiguration\n// documents, rendered together from the master array along with the component\n// configs. Additional nodes get a JoinConfiguration from the join array.\n(import \"common.libsonnet\") + (import \"components.libsonnet\") + {\n\n    kubeadmAPIVersion:: \"kubeadm.k8s.io/v1beta1\",\n    k8sVersion:: \"v1.13.12\",\n\n    // advertise-address is per node, it comes from localAPIEndpoint instead\n    local clusterArgs(args) = { [k]: args[k] for k in std.objectFields(args) if k != \"advertise-address\" },\n\n    // dual-stack nodes outside a cloud have to tell the kubelet both their\n    // addresses, the cloud provider knows them otherwise\n    kubeletExtraArgs:: super.kubeletExtraArgs + {\n        [if $.dualStack && $.cloudProvider == \"\" then \"node-ip\"]: $.ipAddress + \",\" + $.ipAddress6,\n    },\n\n    nodeRegistration:: {\n        name: $.nodeName,\n        [if std.length(std.objectFields($.kubeletExtraArgs)) > 0 then \"kubeletExtraArgs\"]: $.kubeletExtraArgs,\n    },\n\n    initConfiguration:: {\n        apiVersion: $.kubeadmAPIVersion,\n        kind: \"InitConfiguration\",\n        bootstrapTokens: [\n            {\n                token: $.token,\n                ttl: $.tokenTTL,\n                usages: $.tokenUsages,\n                groups: $.tokenGroups,\n                [if $.tokenDescription != \"\" then \"description\"]: $.tokenDescription,\n            },\n        ],\n        nodeRegistration: $.nodeRegistration,\n        localAPIEndpoint: {\n            advertiseAddress: $.ipAddress,\n            bindPort: $.apiServerPort,\n        },\n    },\n\n    clusterConfiguration:: {\n        apiVersion: $.kubeadmAPIVersion,\n        kind: \"ClusterConfiguration\",\n        kubernetesVersion: $.targetVersion,\n        clusterName: $.clusterName,\n        controlPlaneEndpoint: $.apiServerEndpoint,\n        networking: $.networking,\n        apiServer: {\n            extraArgs: clusterArgs($.apiServerExtraArgs),\n            certSANs: $.apiServerCertSANs,\n        },\n        controllerManager: {\n            extraArgs: $.controllerManagerExtraArgs,\n        },\n        scheduler: {\n            extraArgs: $.schedulerExtraArgs,\n        },\n        etcd: {\n            [if $.externalEtcd then \"external\"]: {\n                endpoints: $.etcdEndpoints,\n                caFile: $.etcdCAFile,\n                certFile: $.etcdCertFile,\n                keyFile: $.etcdKeyFile,\n            },\n            [if $.localEtcd then \"local\"]: {\n                dataDir: $.etcdDataDir,\n                extraArgs: $.etcdExtraArgs,\n                serverCertSANs: $.etcdServerCertSANs,\n                peerCertSANs: $.etcdServerCertSANs,\n            },\n        },\n    },\n\n    joinConfiguration:: {\n        apiVersion: $.kubeadmAPIVersion,\n        kind: \"JoinConfiguration\",\n        nodeRegistration: $.nodeRegistration,\n        discovery: {\n            bootstrapToken: {\n                token: $.token,\n                apiServerEndpoint: $.apiServerEndpoint,\n                [if std.length($.caCertHashes) > 0 then \"caCertHashes\"]: $.caCertHashes,\n                unsafeSkipCAVerification: $.unsafeSkipCAVerification,\n            },\n        },\n        [if $.role == \"control-plane\" then \"controlPlane\"]: {\n            localAPIEndpoint: {\n                advertiseAddress: $.ipAddress,\n                bindPort: $.apiServerPort,\n            },\n        },\n    },\n\n    master:: [$.initConfiguration, $.clusterConfiguration] +\n        (if $.components then [$.kubeletComponentConfig, $.kubeProxyComponentConfig] else []),\n    join:: [$.joinConfiguration],\n\n}\n"),
+	}
+	file6 := &embedded.EmbeddedFile{
+		Filename:    "kubeadm-v1beta2.libsonnet",
+		FileModTime: time.Unix(1792293122, 0),
+		Content:     string("// kubeadm.k8s.io/v1beta2 (kubeadm 1.15 - 1.21)\n// Same layout as v1beta1 for everything this template sets.\n(import \"kubeadm-v1beta1.libsonnet\") + {\n\n    kubeadmAPIVersion:: \"kubeadm.k8s.io/v1beta2\",\n    k8sVersion:: \"v1.18.20\",\n\n}\n"),
+	}
+	file7 := &embedded.EmbeddedFile{
+		Filename:    "kubeadm-v1beta3.libsonnet",
+		FileModTime: time.Unix(1792293122, 0),
+		Content:     string("// kubeadm.k8s.io/v1beta3 (kubeadm 1.22+)\n(import \"kubeadm-v1beta2.libsonnet\") + {\n\n    kubeadmAPIVersion:: \"kubeadm.k8s.io/v1beta3\",\n    k8sVersion:: \"v1.22.17\",\n\n}\n"),
+	}
+	file8 := &embedded.EmbeddedFile{
+		Filename:    "kubeadm.libsonnet",
+		FileModTime: time.Unix(1792295413, 0),
+		Content:     string("// kubeadm.k8s.io/v1alpha1 MasterConfiguration (kubeadm 1.8 - 1.10)\n// field names of the outermost object can't refer to $\nlocal components = std.extVar(\"components\") == \"true\";\n\n(import \"common.libsonnet\") + (import \"components.libsonnet\") + {\n\n    apiVersion: \"kubeadm.k8s.io/v1alpha1\",\n    kind: \"MasterConfiguration\",\n    kubernetesVersion: $.targetVersion,\n    nodeName::: super.nodeName,\n    token::: super.token,\n    tokenTTL::: super.tokenTTL,\n    tokenUsages::: super.tokenUsages,\n    tokenGroups::: super.tokenGroups,\n    api: {\n        advertiseAddress: \"0.0.0.0\",\n    },\n    apiServerExtraArgs::: super.apiServerExtraArgs,\n    controllerManagerExtraArgs::: super.controllerManagerExtraArgs,\n    schedulerExtraArgs::: super.schedulerExtraArgs,\n    apiServerCertSANs::: super.apiServerCertSANs,\n    cloudProvider::: super.cloudProvider,\n    networking::: super.networking,\n    etcd: {\n        [if $.externalEtcd then \"endpoints\"]: $.etcdEndpoints,\n        [if $.externalEtcd then \"caFile\"]: $.etcdCAFile,\n        [if $.externalEtcd then \"certFile\"]: $.etcdCertFile,\n        [if $.externalEtcd then \"keyFile\"]: $.etcdKeyFile,\n        [if $.localEtcd then \"dataDir\"]: $.etcdDataDir,\n        [if $.localEtcd then \"extraArgs\"]: $.etcdExtraArgs,\n        [if $.localEtcd then \"serverCertSANs\"]: $.etcdServerCertSANs,\n        [if $.localEtcd then \"peerCertSANs\"]: $.etcdServerCertSANs,\n    },\n    // kubeadm 1.10 takes the component configs inline\n    [if components then \"kubeletConfiguration\"]: {\n        baseConfig: $.kubeletConfigurationSpec,\n    },\n    [if components then \"kubeProxy\"]: {\n        config: $.kubeProxyConfigurationSpec,\n    },\n\n\n}\n"),
+	}
+
+	// define dirs
+	dir1 := &embedded.EmbeddedDir{
+		Filename:   "",
+		DirModTime: time.Unix(1792296352, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file2, // "common.libsonnet"
+			file3, // "components.libsonnet"
+			file4, // "kubeadm-join.libsonnet"
+			file5, // "kubeadm-v1beta1.libsonnet"
+			file6, // "kubeadm-v1beta2.libsonnet"
+			file7, // "kubeadm-v1beta3.libsonnet"
+			file8, // "kubeadm.libsonnet"
+
+		},
+	}
+
+	// link ChildDirs
+	dir1.ChildDirs = []*embedded.EmbeddedDir{}
+
+	// register embeddedBox
+	embedded.RegisterEmbeddedBox(`../../lib`, &embedded.EmbeddedBox{
+		Name: `../../lib`,
+		Time: time.Unix(1792296352, 0),
+		Dirs: map[string]*embedded.EmbeddedDir{
+			"": dir1,
+		},
+		Files: map[string]*embedded.EmbeddedFile{
+			"common.libsonnet":          file2,
+			"components.libsonnet":      file3,
+			"kubeadm-join.libsonnet":    file4,
+			"kubeadm-v1beta1.libsonnet": file5,
+			"kubeadm-v1beta2.libsonnet": file6,
+			"kubeadm-v1beta3.libsonnet": file7,
+			"kubeadm.libsonnet":         file8,
+		},
+	})
+}
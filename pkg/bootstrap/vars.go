@@ -0,0 +1,115 @@
+package bootstrap
+
+import (
+	"fmt"
+	"regexp"
+
+	log "github.com/Sirupsen/logrus"
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+// TemplateVars are extra inputs for custom templates, e.g. an image repository
+// or feature gates. Str values are passed as strings, Code values are jsonnet
+// expressions. Top-level arguments are only used by templates that evaluate
+// to a function
+type TemplateVars struct {
+	ExtStr  map[string]string
+	ExtCode map[string]string
+	TLAStr  map[string]string
+	TLACode map[string]string
+}
+
+// extVarRef matches std.extVar calls with a literal name
+var extVarRef = regexp.MustCompile(`std\.extVar\(\s*["']([^"']+)["']\s*\)`)
+
+// set passes the variables to the vm. They can't replace the built in ext
+// vars, and each name can only be set once
+func (v TemplateVars) set(vm *jsonnet.VM, extVars, extCode map[string]string) error {
+	ext := map[string]bool{}
+	for name := range extVars {
+		ext[name] = true
+	}
+	for name := range extCode {
+		ext[name] = true
+	}
+
+	for name, value := range v.ExtStr {
+		if ext[name] {
+			return fmt.Errorf("ext var %s is set by kubeadm-bootstrap and can't be overridden", name)
+		}
+		vm.ExtVar(name, value)
+	}
+	for name, value := range v.ExtCode {
+		if ext[name] {
+			return fmt.Errorf("ext var %s is set by kubeadm-bootstrap and can't be overridden", name)
+		}
+		if _, ok := v.ExtStr[name]; ok {
+			return fmt.Errorf("ext var %s is already set", name)
+		}
+		vm.ExtCode(name, value)
+	}
+
+	for name, value := range v.TLAStr {
+		vm.TLAVar(name, value)
+	}
+	for name, value := range v.TLACode {
+		if _, ok := v.TLAStr[name]; ok {
+			return fmt.Errorf("top-level arg %s is already set", name)
+		}
+		vm.TLACode(name, value)
+	}
+
+	return nil
+}
+
+// reportUnused logs the variables that the template and its imports never
+// read
+func (v TemplateVars) reportUnused(filename, snippet string, imports []string) {
+	used := map[string]bool{}
+	for _, source := range append([]string{snippet}, imports...) {
+		for _, ref := range extVarRef.FindAllStringSubmatch(source, -1) {
+			used[ref[1]] = true
+		}
+	}
+
+	for _, vars := range []map[string]string{v.ExtStr, v.ExtCode} {
+		for name := range vars {
+			if !used[name] {
+				log.Debug("Ext var ", name, " is not used by the template")
+			}
+		}
+	}
+
+	// jsonnet rejects args that aren't parameters, but ignores them all if the
+	// template isn't a function
+	if isFunction(filename, snippet) {
+		return
+	}
+	for _, vars := range []map[string]string{v.TLAStr, v.TLACode} {
+		for name := range vars {
+			log.Debug("Top-level arg ", name, " is ignored, the template is not a function")
+		}
+	}
+}
+
+// isFunction returns true if a template evaluates to a function, which is
+// what top-level args are passed to
+func isFunction(filename, snippet string) bool {
+	node, err := jsonnet.SnippetToAST(filename, snippet)
+	if err != nil {
+		return false
+	}
+
+	// skip over any top-level locals
+	for {
+		local, ok := node.(*ast.Local)
+		if !ok {
+			break
+		}
+		node = local.Body
+	}
+
+	_, ok := node.(*ast.Function)
+	return ok
+}
@@ -0,0 +1,56 @@
+package certs
+
+import (
+	"crypto/x509"
+	"net"
+	"sort"
+)
+
+// Drift is how the SANs of a certificate differ from the expected ones. SANs
+// are written as DNS:name or IP:address
+type Drift struct {
+	// Missing are the expected SANs the certificate lacks
+	Missing []string
+	// Extra are the SANs of the certificate that aren't expected
+	Extra []string
+}
+
+// None returns true if the certificate has exactly the expected SANs
+func (d Drift) None() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0
+}
+
+// CheckSANs compares the SANs of a certificate with the expected names and
+// addresses
+func CheckSANs(cert *x509.Certificate, names []string, ips []net.IP) Drift {
+	expected := sans(names, ips)
+	actual := sans(cert.DNSNames, cert.IPAddresses)
+
+	var d Drift
+	for san := range expected {
+		if !actual[san] {
+			d.Missing = append(d.Missing, san)
+		}
+	}
+	for san := range actual {
+		if !expected[san] {
+			d.Extra = append(d.Extra, san)
+		}
+	}
+	sort.Strings(d.Missing)
+	sort.Strings(d.Extra)
+	return d
+}
+
+// sans returns the set of SANs for names and addresses. Addresses are
+// compared in their canonical form, so ::1 matches 0:0:0:0:0:0:0:1
+func sans(names []string, ips []net.IP) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range names {
+		set["DNS:"+name] = true
+	}
+	for _, ip := range ips {
+		set["IP:"+ip.String()] = true
+	}
+	return set
+}
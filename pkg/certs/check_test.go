@@ -0,0 +1,61 @@
+package certs
+
+import (
+	"crypto/x509"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestCheckSANs(t *testing.T) {
+	cert := &x509.Certificate{
+		DNSNames:    []string{"master1", "kubernetes", "old-api.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("10.96.0.1"), net.ParseIP("0:0:0:0:0:0:0:1")},
+	}
+
+	tests := []struct {
+		name  string
+		names []string
+		ips   []string
+		want  Drift
+	}{
+		{
+			"matching",
+			[]string{"kubernetes", "master1", "old-api.example.com"},
+			[]string{"::1", "10.96.0.1"},
+			Drift{},
+		},
+		{
+			"missing",
+			[]string{"master1", "kubernetes", "old-api.example.com", "k1-api.example.com"},
+			[]string{"10.96.0.1", "::1", "10.0.0.1"},
+			Drift{Missing: []string{"DNS:k1-api.example.com", "IP:10.0.0.1"}},
+		},
+		{
+			"extra",
+			[]string{"master1", "kubernetes"},
+			[]string{"10.96.0.1"},
+			Drift{Extra: []string{"DNS:old-api.example.com", "IP:::1"}},
+		},
+		{
+			"both",
+			[]string{"master1", "kubernetes", "k1-api.example.com"},
+			[]string{"10.96.0.1", "::1"},
+			Drift{Missing: []string{"DNS:k1-api.example.com"}, Extra: []string{"DNS:old-api.example.com"}},
+		},
+	}
+
+	for _, test := range tests {
+		var ips []net.IP
+		for _, ip := range test.ips {
+			ips = append(ips, net.ParseIP(ip))
+		}
+		got := CheckSANs(cert, test.names, ips)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+		if got.None() != (test.name == "matching") {
+			t.Errorf("%s: unexpected None() %v", test.name, got.None())
+		}
+	}
+}
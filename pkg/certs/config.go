@@ -0,0 +1,247 @@
+package certs
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Names are what the certificates of a master are issued for, read from its
+// kubeadm config
+type Names struct {
+	NodeName         string
+	AdvertiseAddress string
+	// CertSANs are the extra API server names and addresses
+	CertSANs []string
+	// ControlPlaneEndpoint is the host of the endpoint nodes join through,
+	// which kubeadm always adds to the API server certificate
+	ControlPlaneEndpoint string
+	ServiceSubnet        string
+	DNSDomain            string
+	// LocalEtcd is set for stacked etcd, which has its own server and peer
+	// certificates
+	LocalEtcd      bool
+	EtcdServerSANs []string
+	EtcdPeerSANs   []string
+}
+
+// NamesFromConfig reads the names from the documents of a master's kubeadm
+// config, as returned by validate.Parse
+func NamesFromConfig(docs []interface{}) (Names, error) {
+	n := Names{ServiceSubnet: "10.96.0.0/12", DNSDomain: "cluster.local"}
+	found := false
+
+	for _, value := range docs {
+		doc, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch doc["kind"] {
+		case "MasterConfiguration":
+			found = true
+			n.NodeName = stringAt(doc, "nodeName")
+			n.AdvertiseAddress = stringAt(doc, "api", "advertiseAddress")
+			if unspecified(n.AdvertiseAddress) {
+				n.AdvertiseAddress = stringAt(doc, "apiServerExtraArgs", "advertise-address")
+			}
+			n.CertSANs = stringsAt(doc, "apiServerCertSANs")
+			n.ControlPlaneEndpoint = endpointHost(stringAt(doc, "api", "controlPlaneEndpoint"))
+			n.networking(doc)
+			// v1alpha1 uses external etcd if it has endpoints
+			if len(stringsAt(doc, "etcd", "endpoints")) == 0 {
+				n.LocalEtcd = true
+				n.EtcdServerSANs = stringsAt(doc, "etcd", "serverCertSANs")
+				n.EtcdPeerSANs = stringsAt(doc, "etcd", "peerCertSANs")
+			}
+
+		case "InitConfiguration":
+			n.NodeName = stringAt(doc, "nodeRegistration", "name")
+			n.AdvertiseAddress = stringAt(doc, "localAPIEndpoint", "advertiseAddress")
+
+		case "ClusterConfiguration":
+			found = true
+			n.CertSANs = stringsAt(doc, "apiServer", "certSANs")
+			n.ControlPlaneEndpoint = endpointHost(stringAt(doc, "controlPlaneEndpoint"))
+			n.networking(doc)
+			if valueAt(doc, []string{"etcd", "local"}) != nil {
+				n.LocalEtcd = true
+				n.EtcdServerSANs = stringsAt(doc, "etcd", "local", "serverCertSANs")
+				n.EtcdPeerSANs = stringsAt(doc, "etcd", "local", "peerCertSANs")
+			}
+		}
+	}
+
+	if !found {
+		return n, fmt.Errorf("no MasterConfiguration or ClusterConfiguration found in config")
+	}
+	if n.NodeName == "" {
+		return n, fmt.Errorf("config has no node name")
+	}
+	return n, nil
+}
+
+// networking reads the service subnet and DNS domain, keeping the defaults if
+// they aren't set
+func (n *Names) networking(doc map[string]interface{}) {
+	if subnet := stringAt(doc, "networking", "serviceSubnet"); subnet != "" {
+		n.ServiceSubnet = subnet
+	}
+	if domain := stringAt(doc, "networking", "dnsDomain"); domain != "" {
+		n.DNSDomain = domain
+	}
+}
+
+// APIServerSANs returns the names and addresses of the API server certificate:
+// the node name and address, the names of the kubernetes service and its IP,
+// the control plane endpoint and the extra cert SANs, as kubeadm would issue it
+func (n Names) APIServerSANs() ([]string, []net.IP, error) {
+	serviceIP, err := serviceIP(n.ServiceSubnet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := []string{
+		n.NodeName,
+		"kubernetes",
+		"kubernetes.default",
+		"kubernetes.default.svc",
+		"kubernetes.default.svc." + n.DNSDomain,
+	}
+	ips := []net.IP{serviceIP}
+	if ip := net.ParseIP(n.AdvertiseAddress); ip != nil && !ip.IsUnspecified() {
+		ips = append(ips, ip)
+	}
+	sans := n.CertSANs
+	if n.ControlPlaneEndpoint != "" {
+		sans = append([]string{n.ControlPlaneEndpoint}, sans...)
+	}
+	dnsNames, sanIPs := splitSANs(sans)
+	return dedupeNames(append(names, dnsNames...)), dedupeIPs(append(ips, sanIPs...)), nil
+}
+
+// EtcdSANs returns the names and addresses of the etcd server or peer
+// certificate of local etcd, which also serves on localhost
+func (n Names) EtcdSANs(peer bool) ([]string, []net.IP) {
+	sans := n.EtcdServerSANs
+	if peer {
+		sans = n.EtcdPeerSANs
+	}
+
+	names := []string{n.NodeName}
+	var ips []net.IP
+	// peers only talk to each other on the node address
+	if !peer {
+		names = append(names, "localhost")
+		ips = append(ips, net.IPv4(127, 0, 0, 1), net.IPv6loopback)
+	}
+	if ip := net.ParseIP(n.AdvertiseAddress); ip != nil && !ip.IsUnspecified() {
+		ips = append(ips, ip)
+	}
+	dnsNames, sanIPs := splitSANs(sans)
+	return dedupeNames(append(names, dnsNames...)), dedupeIPs(append(ips, sanIPs...))
+}
+
+// endpointHost returns the host of a host:port endpoint, which may have no
+// port
+func endpointHost(endpoint string) string {
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(endpoint, "["), "]")
+}
+
+// serviceIP returns the first address of the service CIDR, the API server's
+// service IP. Dual-stack clusters use the first CIDR
+func serviceIP(subnet string) (net.IP, error) {
+	cidr := strings.TrimSpace(strings.Split(subnet, ",")[0])
+	_, service, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service CIDR %s", cidr)
+	}
+
+	ip := make(net.IP, len(service.IP))
+	copy(ip, service.IP)
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+	return ip, nil
+}
+
+// splitSANs separates SANs into DNS names and IP addresses
+func splitSANs(sans []string) ([]string, []net.IP) {
+	var names []string
+	var ips []net.IP
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			ips = append(ips, ip)
+			continue
+		}
+		names = append(names, san)
+	}
+	return names, ips
+}
+
+func dedupeNames(names []string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+func dedupeIPs(ips []net.IP) []net.IP {
+	var unique []net.IP
+	seen := map[string]bool{}
+	for _, ip := range ips {
+		if key := ip.String(); !seen[key] {
+			seen[key] = true
+			unique = append(unique, ip)
+		}
+	}
+	return unique
+}
+
+// stringAt returns the string at a path of fields in a document, or ""
+func stringAt(doc map[string]interface{}, path ...string) string {
+	s, _ := valueAt(doc, path).(string)
+	return s
+}
+
+// stringsAt returns the list of strings at a path of fields in a document
+func stringsAt(doc map[string]interface{}, path ...string) []string {
+	items, _ := valueAt(doc, path).([]interface{})
+	var strs []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+func valueAt(doc map[string]interface{}, path []string) interface{} {
+	var value interface{} = doc
+	for _, field := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[field]
+	}
+	return value
+}
+
+// unspecified returns true for an empty address or one that means any
+// address, such as 0.0.0.0
+func unspecified(address string) bool {
+	ip := net.ParseIP(address)
+	return ip == nil || ip.IsUnspecified()
+}
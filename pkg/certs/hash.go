@@ -0,0 +1,37 @@
+package certs
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadCertificate reads the first PEM encoded certificate from a file
+func LoadCertificate(path string) (*x509.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM encoded certificate found in %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// CACertHash returns the SHA-256 hash of a certificate's Subject Public Key Info
+// in the sha256:<hex> format kubeadm uses for discovery token CA cert hashes.
+// This is the same pin kubeadm prints after kubeadm init.
+func CACertHash(cert *x509.Certificate) string {
+	spkiHash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256:" + hex.EncodeToString(spkiHash[:])
+}
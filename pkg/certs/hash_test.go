@@ -0,0 +1,25 @@
+package certs
+
+import "testing"
+
+func TestCACertHash(t *testing.T) {
+	cert, err := LoadCertificate("testdata/ca.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// openssl x509 -in testdata/ca.crt -pubkey -noout |
+	//   openssl pkey -pubin -outform der | sha256sum
+	want := "sha256:cb5079d5e210c425a4305d1a67d749409c182f02932066c0e869d85c4286ccb1"
+	if got := CACertHash(cert); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestLoadCertificateErrors(t *testing.T) {
+	for _, path := range []string{"testdata/missing.crt", "hash_test.go"} {
+		if _, err := LoadCertificate(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// The key types certificates can be generated with
+const (
+	KeyRSA2048   = "rsa-2048"
+	KeyRSA3072   = "rsa-3072"
+	KeyRSA4096   = "rsa-4096"
+	KeyECDSAP256 = "ecdsa-p256"
+	KeyECDSAP384 = "ecdsa-p384"
+)
+
+// keyGenerators create a private key of each key type
+var keyGenerators = map[string]func() (crypto.Signer, error){
+	KeyRSA2048:   func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+	KeyRSA3072:   func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 3072) },
+	KeyRSA4096:   func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 4096) },
+	KeyECDSAP256: func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+	KeyECDSAP384: func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P384(), rand.Reader) },
+}
+
+// SupportedKeyTypes returns the key types certificates can be generated with
+func SupportedKeyTypes() []string {
+	var types []string
+	for keyType := range keyGenerators {
+		types = append(types, keyType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// checkKeyType returns an error for unknown key types
+func checkKeyType(keyType string) error {
+	if _, ok := keyGenerators[keyType]; !ok {
+		return fmt.Errorf("unknown key type %s, must be one of: %s", keyType, strings.Join(SupportedKeyTypes(), ", "))
+	}
+	return nil
+}
+
+// newKey generates a private key of a key type
+func newKey(keyType string) (crypto.Signer, error) {
+	if err := checkKeyType(keyType); err != nil {
+		return nil, err
+	}
+	return keyGenerators[keyType]()
+}
+
+// encodeKey PEM encodes a private key, in the PKCS #1 or SEC 1 form kubeadm
+// writes
+func encodeKey(key crypto.Signer) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+// encodePublicKey PEM encodes the public key of a private key
+func encodePublicKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadKey reads a PEM encoded RSA or ECDSA private key from a file
+func LoadKey(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM encoded private key found in %s", path)
+		}
+
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("unsupported private key type %T in %s", key, path)
+			}
+			return signer, nil
+		}
+	}
+}
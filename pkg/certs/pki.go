@@ -0,0 +1,303 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultDir is where kubeadm keeps the cluster PKI
+const DefaultDir = "/etc/kubernetes/pki"
+
+// The default validity of CAs and leaf certificates, as kubeadm issues them
+const (
+	DefaultCAValidity   = 10 * 365 * 24 * time.Hour
+	DefaultCertValidity = 365 * 24 * time.Hour
+)
+
+// Options are how the PKI is generated
+type Options struct {
+	// Dir is where the files are written, kubeadm's certificatesDir
+	Dir     string
+	KeyType string
+	// CAValidity and CertValidity are how long CAs and leaf certificates are
+	// valid. Leaf certificates never outlive their CA
+	CAValidity   time.Duration
+	CertValidity time.Duration
+	// Renew regenerates leaf certificates that already exist. CAs and the
+	// service account key are always kept, so they can be generated once and
+	// shared by every master. Leaf certificates of a CA that had to be
+	// generated are always regenerated, as they no longer chain to it
+	Renew bool
+}
+
+// Cert is a certificate of the PKI
+type Cert struct {
+	// Name is the path of the certificate in the PKI directory without its
+	// extension, e.g. etcd/server
+	Name string
+	// CA is the name of the CA that signs it, or empty if it is a CA
+	CA           string
+	CommonName   string
+	Organization []string
+	Usages       []x509.ExtKeyUsage
+	DNSNames     []string
+	IPs          []net.IP
+}
+
+// Result is a file of the PKI and whether it was generated or kept
+type Result struct {
+	Name string
+	Kept bool
+}
+
+// serviceAccountKey is the name of the key pair that signs service account
+// tokens
+const serviceAccountKey = "sa"
+
+var (
+	serverAuth = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	clientAuth = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	bothAuth   = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	masters    = []string{"system:masters"}
+)
+
+// Certs returns the certificates kubeadm expects a master to have, each
+// after the CA that signs it. Local etcd adds its server, peer and
+// healthcheck certificates
+func Certs(n Names) ([]Cert, error) {
+	apiServerNames, apiServerIPs, err := n.APIServerSANs()
+	if err != nil {
+		return nil, err
+	}
+
+	certs := []Cert{
+		{Name: "ca", CommonName: "kubernetes"},
+		{Name: "apiserver", CA: "ca", CommonName: "kube-apiserver", Usages: serverAuth, DNSNames: apiServerNames, IPs: apiServerIPs},
+		{Name: "apiserver-kubelet-client", CA: "ca", CommonName: "kube-apiserver-kubelet-client", Organization: masters, Usages: clientAuth},
+		{Name: "front-proxy-ca", CommonName: "front-proxy-ca"},
+		{Name: "front-proxy-client", CA: "front-proxy-ca", CommonName: "front-proxy-client", Usages: clientAuth},
+		{Name: "etcd/ca", CommonName: "etcd-ca"},
+		{Name: "apiserver-etcd-client", CA: "etcd/ca", CommonName: "kube-apiserver-etcd-client", Organization: masters, Usages: clientAuth},
+	}
+
+	if n.LocalEtcd {
+		serverNames, serverIPs := n.EtcdSANs(false)
+		peerNames, peerIPs := n.EtcdSANs(true)
+		certs = append(certs,
+			Cert{Name: "etcd/server", CA: "etcd/ca", CommonName: n.NodeName, Usages: bothAuth, DNSNames: serverNames, IPs: serverIPs},
+			Cert{Name: "etcd/peer", CA: "etcd/ca", CommonName: n.NodeName, Usages: bothAuth, DNSNames: peerNames, IPs: peerIPs},
+			Cert{Name: "etcd/healthcheck-client", CA: "etcd/ca", CommonName: "kube-etcd-healthcheck-client", Organization: masters, Usages: clientAuth},
+		)
+	}
+
+	return certs, nil
+}
+
+// Generate writes the CAs, leaf certificates and service account key pair
+// kubeadm expects into the PKI directory. Existing CAs and service account
+// keys are reused, and existing leaf certificates are kept unless renewing or
+// their CA was missing
+func Generate(n Names, opts Options) ([]Result, error) {
+	if err := checkKeyType(opts.KeyType); err != nil {
+		return nil, err
+	}
+	if opts.CAValidity <= 0 || opts.CertValidity <= 0 {
+		return nil, fmt.Errorf("certificate validity must be positive")
+	}
+
+	certs, err := Certs(n)
+	if err != nil {
+		return nil, err
+	}
+
+	type signer struct {
+		cert *x509.Certificate
+		key  crypto.Signer
+	}
+	cas := map[string]signer{}
+	// generated are the CAs that didn't exist, whose existing leaf
+	// certificates were signed by another CA
+	generated := map[string]bool{}
+	now := time.Now()
+
+	var results []Result
+	for _, c := range certs {
+		certPath, keyPath := Paths(opts.Dir, c.Name)
+		exists, err := pairExists(certPath, keyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.CA == "" {
+			if exists {
+				cert, err := LoadCertificate(certPath)
+				if err != nil {
+					return nil, err
+				}
+				key, err := LoadKey(keyPath)
+				if err != nil {
+					return nil, err
+				}
+				cas[c.Name] = signer{cert, key}
+				results = append(results, Result{Name: c.Name, Kept: true})
+				continue
+			}
+
+			key, err := newKey(opts.KeyType)
+			if err != nil {
+				return nil, err
+			}
+			cert, err := newCert(c, key, nil, nil, opts.CAValidity, now)
+			if err != nil {
+				return nil, fmt.Errorf("error generating %s: %v", c.Name, err)
+			}
+			if err := writePair(certPath, keyPath, cert, key); err != nil {
+				return nil, err
+			}
+			cas[c.Name] = signer{cert, key}
+			generated[c.Name] = true
+			results = append(results, Result{Name: c.Name})
+			continue
+		}
+
+		if exists && !opts.Renew && !generated[c.CA] {
+			results = append(results, Result{Name: c.Name, Kept: true})
+			continue
+		}
+
+		ca := cas[c.CA]
+		key, err := newKey(opts.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := newCert(c, key, ca.cert, ca.key, opts.CertValidity, now)
+		if err != nil {
+			return nil, fmt.Errorf("error generating %s: %v", c.Name, err)
+		}
+		if err := writePair(certPath, keyPath, cert, key); err != nil {
+			return nil, err
+		}
+		results = append(results, Result{Name: c.Name})
+	}
+
+	result, err := serviceAccountKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, result), nil
+}
+
+// Paths returns the certificate and key files of a certificate in a PKI
+// directory
+func Paths(dir, name string) (string, string) {
+	base := filepath.Join(dir, filepath.FromSlash(name))
+	return base + ".crt", base + ".key"
+}
+
+// serviceAccountKeys writes the key pair that signs service account tokens,
+// unless the private key already exists
+func serviceAccountKeys(opts Options) (Result, error) {
+	keyPath := filepath.Join(opts.Dir, serviceAccountKey+".key")
+	pubPath := filepath.Join(opts.Dir, serviceAccountKey+".pub")
+
+	if _, err := os.Stat(keyPath); err == nil {
+		return Result{Name: serviceAccountKey, Kept: true}, nil
+	}
+
+	key, err := newKey(opts.KeyType)
+	if err != nil {
+		return Result{}, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return Result{}, err
+	}
+	pubPEM, err := encodePublicKey(key)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := writeFile(keyPath, keyPEM, 0600); err != nil {
+		return Result{}, err
+	}
+	if err := writeFile(pubPath, pubPEM, 0644); err != nil {
+		return Result{}, err
+	}
+	return Result{Name: serviceAccountKey}, nil
+}
+
+// newCert issues a certificate for a key. It is self-signed if there is no
+// parent, and never valid for longer than its parent
+func newCert(c Cert, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer, validity time.Duration, now time.Time) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: c.CommonName, Organization: c.Organization},
+		NotBefore:    now.UTC(),
+		NotAfter:     now.Add(validity).UTC(),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  c.Usages,
+		DNSNames:     c.DNSNames,
+		IPAddresses:  c.IPs,
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else if template.NotAfter.After(parent.NotAfter) {
+		template.NotAfter = parent.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// pairExists returns true if both files of a certificate exist, and an error
+// if only one of them does
+func pairExists(certPath, keyPath string) (bool, error) {
+	_, certErr := os.Stat(certPath)
+	_, keyErr := os.Stat(keyPath)
+	if certErr == nil && keyErr == nil {
+		return true, nil
+	}
+	if certErr == nil || keyErr == nil {
+		return false, fmt.Errorf("found only one of %s and %s, remove it or provide the other", certPath, keyPath)
+	}
+	return false, nil
+}
+
+func writePair(certPath, keyPath string, cert *x509.Certificate, key crypto.Signer) error {
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(keyPath, keyPEM, 0600); err != nil {
+		return err
+	}
+	return writeFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644)
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, perm)
+}
@@ -0,0 +1,270 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNames = Names{
+	NodeName:             "master1",
+	AdvertiseAddress:     "10.0.0.1",
+	CertSANs:             []string{"k1-api.example.com", "192.0.2.1"},
+	ControlPlaneEndpoint: "k1.lb.example.com",
+	ServiceSubnet:        "10.96.0.0/12",
+	DNSDomain:            "cluster.local",
+	LocalEtcd:            true,
+}
+
+// testPKI returns a temporary PKI directory and options to generate it with
+// small keys
+func testPKI(t *testing.T) (Options, func()) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		Dir:          dir,
+		KeyType:      KeyECDSAP256,
+		CAValidity:   DefaultCAValidity,
+		CertValidity: DefaultCertValidity,
+	}
+	return opts, func() { os.RemoveAll(dir) }
+}
+
+// generate runs Generate, returning the names of the files that were
+// generated and kept
+func generate(t *testing.T, opts Options) (generated, kept []string) {
+	results, err := Generate(testNames, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Kept {
+			kept = append(kept, result.Name)
+		} else {
+			generated = append(generated, result.Name)
+		}
+	}
+	return generated, kept
+}
+
+// load reads a certificate of the PKI
+func load(t *testing.T, opts Options, name string) *x509.Certificate {
+	certPath, _ := Paths(opts.Dir, name)
+	cert, err := LoadCertificate(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// checkChains verifies every leaf certificate was signed by its CA
+func checkChains(t *testing.T, opts Options) {
+	certs, err := Certs(testNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range certs {
+		if c.CA == "" {
+			continue
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(load(t, opts, c.CA))
+		_, err := load(t, opts, c.Name).Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+		if err != nil {
+			t.Errorf("%s doesn't chain to %s: %v", c.Name, c.CA, err)
+		}
+	}
+}
+
+var allFiles = []string{
+	"ca", "apiserver", "apiserver-kubelet-client",
+	"front-proxy-ca", "front-proxy-client",
+	"etcd/ca", "apiserver-etcd-client",
+	"etcd/server", "etcd/peer", "etcd/healthcheck-client",
+	"sa",
+}
+
+func TestGenerate(t *testing.T) {
+	opts, cleanup := testPKI(t)
+	defer cleanup()
+
+	generated, kept := generate(t, opts)
+	if !reflect.DeepEqual(generated, allFiles) || len(kept) != 0 {
+		t.Errorf("generated %v, kept %v", generated, kept)
+	}
+	checkChains(t, opts)
+
+	apiserver := load(t, opts, "apiserver")
+	names, ips, err := testNames.APIServerSANs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift := CheckSANs(apiserver, names, ips); !drift.None() {
+		t.Errorf("unexpected API server SANs %+v", drift)
+	}
+	if !apiserver.NotAfter.Before(load(t, opts, "ca").NotAfter) {
+		t.Error("expected the API server certificate to expire before its CA")
+	}
+
+	for _, file := range []string{"sa.key", "sa.pub"} {
+		if _, err := os.Stat(filepath.Join(opts.Dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestGenerateKeepsExisting(t *testing.T) {
+	opts, cleanup := testPKI(t)
+	defer cleanup()
+
+	generate(t, opts)
+	before := load(t, opts, "apiserver")
+
+	generated, kept := generate(t, opts)
+	if len(generated) != 0 || !reflect.DeepEqual(kept, allFiles) {
+		t.Errorf("generated %v, kept %v", generated, kept)
+	}
+	if !load(t, opts, "apiserver").Equal(before) {
+		t.Error("expected the API server certificate to be kept")
+	}
+}
+
+func TestGenerateRenew(t *testing.T) {
+	opts, cleanup := testPKI(t)
+	defer cleanup()
+
+	generate(t, opts)
+	ca := load(t, opts, "ca")
+	apiserver := load(t, opts, "apiserver")
+
+	opts.Renew = true
+	generated, kept := generate(t, opts)
+	if want := []string{"ca", "front-proxy-ca", "etcd/ca", "sa"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+	if len(generated) != len(allFiles)-len(kept) {
+		t.Errorf("generated %v", generated)
+	}
+	if !load(t, opts, "ca").Equal(ca) {
+		t.Error("expected the CA to be kept")
+	}
+	if load(t, opts, "apiserver").Equal(apiserver) {
+		t.Error("expected the API server certificate to be renewed")
+	}
+	checkChains(t, opts)
+}
+
+func TestGenerateMissingCA(t *testing.T) {
+	opts, cleanup := testPKI(t)
+	defer cleanup()
+
+	generate(t, opts)
+	for _, file := range []string{"etcd/ca.crt", "etcd/ca.key"} {
+		if err := os.Remove(filepath.Join(opts.Dir, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the leaf certificates of the etcd CA are reissued, the others kept
+	generated, _ := generate(t, opts)
+	want := []string{"etcd/ca", "apiserver-etcd-client", "etcd/server", "etcd/peer", "etcd/healthcheck-client"}
+	if !reflect.DeepEqual(generated, want) {
+		t.Errorf("generated %v, want %v", generated, want)
+	}
+	checkChains(t, opts)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	opts, cleanup := testPKI(t)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		modify func(*Options)
+		err    string
+	}{
+		{"unknown key type", func(o *Options) { o.KeyType = "dsa-1024" }, "unknown key type dsa-1024"},
+		{"no CA validity", func(o *Options) { o.CAValidity = 0 }, "certificate validity must be positive"},
+		{"negative cert validity", func(o *Options) { o.CertValidity = -time.Hour }, "certificate validity must be positive"},
+	}
+	for _, test := range tests {
+		o := opts
+		test.modify(&o)
+		if _, err := Generate(testNames, o); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	// a certificate without its key
+	certPath, _ := Paths(opts.Dir, "ca")
+	if err := writeFile(certPath, []byte("certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(testNames, opts); err == nil || !strings.Contains(err.Error(), "found only one of") {
+		t.Errorf("got error %v for a certificate without its key", err)
+	}
+}
+
+func TestKeyTypes(t *testing.T) {
+	rsaBits := func(bits int) func(interface{}) bool {
+		return func(k interface{}) bool {
+			r, ok := k.(*rsa.PrivateKey)
+			return ok && r.N.BitLen() == bits
+		}
+	}
+	curve := func(name string) func(interface{}) bool {
+		return func(k interface{}) bool {
+			e, ok := k.(*ecdsa.PrivateKey)
+			return ok && e.Curve.Params().Name == name
+		}
+	}
+	tests := map[string]func(interface{}) bool{
+		KeyRSA2048:   rsaBits(2048),
+		KeyRSA3072:   rsaBits(3072),
+		KeyRSA4096:   rsaBits(4096),
+		KeyECDSAP256: curve("P-256"),
+		KeyECDSAP384: curve("P-384"),
+	}
+	if len(tests) != len(SupportedKeyTypes()) {
+		t.Fatalf("expected a test for each of %v", SupportedKeyTypes())
+	}
+
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for keyType, check := range tests {
+		key, err := newKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pem, err := encodeKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, keyType+".key")
+		if err := writeFile(path, pem, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadKey(path)
+		if err != nil {
+			t.Errorf("%s: %v", keyType, err)
+			continue
+		}
+		if !check(loaded) {
+			t.Errorf("%s: unexpected key %T", keyType, loaded)
+		}
+	}
+}
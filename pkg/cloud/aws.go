@@ -0,0 +1,94 @@
+package cloud
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AWS probes the EC2 instance metadata service
+type AWS struct {
+	// Endpoint overrides the metadata service address, for testing
+	Endpoint string
+
+	svc *ec2metadata.EC2Metadata
+}
+
+// Name of the cloud provider
+func (a *AWS) Name() string {
+	return "aws"
+}
+
+func (a *AWS) client() (*ec2metadata.EC2Metadata, error) {
+	if a.svc != nil {
+		return a.svc, nil
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := aws.NewConfig().WithHTTPClient(httpClient())
+	if a.Endpoint != "" {
+		cfg = cfg.WithEndpoint(a.Endpoint)
+	}
+	a.svc = ec2metadata.New(sess, cfg)
+	return a.svc, nil
+}
+
+// Available reports whether the EC2 metadata service can be reached
+func (a *AWS) Available() bool {
+	svc, err := a.client()
+	if err != nil {
+		return false
+	}
+	return svc.Available()
+}
+
+// Metadata reads the instance metadata
+func (a *AWS) Metadata() (Metadata, error) {
+	svc, err := a.client()
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	awsHostname, err := svc.GetMetadata("local-hostname")
+	if err != nil {
+		return Metadata{}, err
+	}
+	region, err := svc.Region()
+	if err != nil {
+		return Metadata{}, err
+	}
+	zone, err := svc.GetMetadata("placement/availability-zone")
+	if err != nil {
+		return Metadata{}, err
+	}
+	ip, err := svc.GetMetadata("local-ipv4")
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	md := Metadata{
+		Provider:  "aws",
+		Region:    region,
+		Zone:      zone,
+		IPAddress: ip,
+	}
+
+	// only instances with an IPv6 address have this
+	if ip6, err := svc.GetMetadata("ipv6"); err == nil {
+		md.IPAddress6 = ip6
+	}
+
+	splitHostname := strings.Split(awsHostname, ".")
+	md.Hostname = splitHostname[0] + "." + region + ".compute.internal"
+	if len(splitHostname) >= 3 {
+		md.Domain = splitHostname[1] + "." + splitHostname[2]
+	}
+
+	return md, nil
+}
@@ -0,0 +1,96 @@
+package cloud
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// awsMetadata serves an instance's metadata, with the local hostname of a
+// region outside us-east-1
+func awsMetadata(hostname string) map[string]string {
+	return map[string]string{
+		"/meta-data/instance-id":                 "i-0123456789abcdef0",
+		"/meta-data/local-hostname":              hostname,
+		"/meta-data/placement/availability-zone": "us-west-2a",
+		"/meta-data/local-ipv4":                  "10.0.0.5",
+	}
+}
+
+func TestAWS(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     Metadata
+	}{
+		{
+			"ip-10-0-0-5.us-west-2.compute.internal",
+			Metadata{Hostname: "ip-10-0-0-5.us-west-2.compute.internal", Domain: "us-west-2.compute"},
+		},
+		// us-east-1 hostnames don't have the region
+		{
+			"ip-10-0-0-5.ec2.internal",
+			Metadata{Hostname: "ip-10-0-0-5.us-west-2.compute.internal", Domain: "ec2.internal"},
+		},
+		// a hostname of fewer than 3 labels has no domain
+		{
+			"ip-10-0-0-5.internal",
+			Metadata{Hostname: "ip-10-0-0-5.us-west-2.compute.internal"},
+		},
+		{
+			"ip-10-0-0-5",
+			Metadata{Hostname: "ip-10-0-0-5.us-west-2.compute.internal"},
+		},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(metadataServer(nil, awsMetadata(test.hostname)))
+
+		a := &AWS{Endpoint: server.URL}
+		if !a.Available() {
+			t.Fatal("expected AWS to be detected")
+		}
+		md, err := a.Metadata()
+		if err != nil {
+			t.Fatalf("%s: %v", test.hostname, err)
+		}
+
+		want := test.want
+		want.Provider, want.Region, want.Zone, want.IPAddress = "aws", "us-west-2", "us-west-2a", "10.0.0.5"
+		if md != want {
+			t.Errorf("%s: got %+v, want %+v", test.hostname, md, want)
+		}
+		server.Close()
+	}
+}
+
+func TestAWSIPv6(t *testing.T) {
+	paths := awsMetadata("ip-10-0-0-5.us-west-2.compute.internal")
+	paths["/meta-data/ipv6"] = "2600:1f14::5"
+	server := httptest.NewServer(metadataServer(nil, paths))
+	defer server.Close()
+
+	md, err := (&AWS{Endpoint: server.URL}).Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.IPAddress != "10.0.0.5" || md.IPAddress6 != "2600:1f14::5" {
+		t.Errorf("got addresses %s and %s", md.IPAddress, md.IPAddress6)
+	}
+}
+
+func TestAWSMissingMetadata(t *testing.T) {
+	paths := awsMetadata("ip-10-0-0-5.us-west-2.compute.internal")
+	delete(paths, "/meta-data/local-ipv4")
+	server := httptest.NewServer(metadataServer(nil, paths))
+	defer server.Close()
+
+	if _, err := (&AWS{Endpoint: server.URL}).Metadata(); err == nil {
+		t.Error("expected an error for missing metadata")
+	}
+
+	// another metadata service at the same address
+	other := httptest.NewServer(gceHandler(gceMetadata))
+	defer other.Close()
+	if (&AWS{Endpoint: other.URL}).Available() {
+		t.Error("expected GCE not to be detected as AWS")
+	}
+}
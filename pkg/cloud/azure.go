@@ -0,0 +1,111 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// AzureEndpoint is the address of the Azure instance metadata service
+const AzureEndpoint = "http://169.254.169.254"
+
+const azureAPIVersion = "2021-02-01"
+
+// Azure probes the Azure instance metadata service
+type Azure struct {
+	Endpoint string
+
+	instance *azureInstance
+}
+
+type azureInstance struct {
+	Compute struct {
+		Name     string `json:"name"`
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+	} `json:"compute"`
+	Network struct {
+		Interface []struct {
+			IPv4 struct {
+				IPAddress []struct {
+					PrivateIPAddress string `json:"privateIpAddress"`
+				} `json:"ipAddress"`
+			} `json:"ipv4"`
+			IPv6 struct {
+				IPAddress []struct {
+					PrivateIPAddress string `json:"privateIpAddress"`
+				} `json:"ipAddress"`
+			} `json:"ipv6"`
+		} `json:"interface"`
+	} `json:"network"`
+}
+
+// Name of the cloud provider
+func (a *Azure) Name() string {
+	return "azure"
+}
+
+func (a *Azure) load() error {
+	if a.instance != nil {
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", a.Endpoint+"/metadata/instance?api-version="+azureAPIVersion, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error reading Azure metadata: %s", resp.Status)
+	}
+
+	instance := &azureInstance{}
+	if err := json.NewDecoder(resp.Body).Decode(instance); err != nil {
+		return fmt.Errorf("error decoding Azure metadata: %v", err)
+	}
+	a.instance = instance
+	return nil
+}
+
+// Available reports whether the Azure instance metadata service can be
+// reached. Other clouds use the same address, so this checks the response
+func (a *Azure) Available() bool {
+	return a.load() == nil && a.instance.Compute.Name != ""
+}
+
+// Metadata reads the instance metadata. Azure doesn't expose the node's
+// domain, so it's left for other fact providers
+func (a *Azure) Metadata() (Metadata, error) {
+	if err := a.load(); err != nil {
+		return Metadata{}, err
+	}
+
+	md := Metadata{
+		Provider: "azure",
+		Hostname: a.instance.Compute.Name,
+		Region:   a.instance.Compute.Location,
+		Zone:     a.instance.Compute.Zone,
+	}
+
+	for _, iface := range a.instance.Network.Interface {
+		for _, addr := range iface.IPv4.IPAddress {
+			if md.IPAddress == "" {
+				md.IPAddress = addr.PrivateIPAddress
+			}
+		}
+		for _, addr := range iface.IPv6.IPAddress {
+			if md.IPAddress6 == "" {
+				md.IPAddress6 = addr.PrivateIPAddress
+			}
+		}
+	}
+
+	return md, nil
+}
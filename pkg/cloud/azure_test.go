@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const azureMetadata = `{
+  "compute": {"name": "node1", "location": "westeurope", "zone": "2"},
+  "network": {
+    "interface": [
+      {
+        "ipv4": {"ipAddress": [{"privateIpAddress": "10.1.0.4"}, {"privateIpAddress": "10.1.0.5"}]},
+        "ipv6": {"ipAddress": [{"privateIpAddress": "fd00::4"}]}
+      }
+    ]
+  }
+}`
+
+// azureHandler serves Azure instance metadata, which is only returned to
+// requests with the Metadata header and an API version
+func azureHandler(body string) http.HandlerFunc {
+	serve := metadataServer(nil, map[string]string{"/metadata/instance": body})
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("api-version") == "" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		serve(w, r)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	server := httptest.NewServer(azureHandler(azureMetadata))
+	defer server.Close()
+
+	a := &Azure{Endpoint: server.URL}
+	if !a.Available() {
+		t.Fatal("expected Azure to be detected")
+	}
+
+	md, err := a.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Metadata{
+		Provider:   "azure",
+		Hostname:   "node1",
+		Region:     "westeurope",
+		Zone:       "2",
+		IPAddress:  "10.1.0.4",
+		IPAddress6: "fd00::4",
+	}
+	if md != want {
+		t.Errorf("got %+v, want %+v", md, want)
+	}
+}
+
+func TestAzureNotMatching(t *testing.T) {
+	tests := map[string]http.Handler{
+		// another cloud's metadata service at the same address
+		"other cloud": metadataServer(nil, map[string]string{"/metadata/instance": `{"instance-id": "i-0123"}`}),
+		"not JSON":    metadataServer(nil, map[string]string{"/metadata/instance": "<html></html>"}),
+		"not found":   http.NotFoundHandler(),
+	}
+
+	for name, handler := range tests {
+		server := httptest.NewServer(handler)
+		a := &Azure{Endpoint: server.URL}
+		if a.Available() {
+			t.Errorf("%s: expected Azure not to be detected", name)
+		}
+		if _, err := a.Metadata(); name != "other cloud" && err == nil {
+			t.Errorf("%s: expected an error reading metadata", name)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,63 @@
+package cloud
+
+import (
+	"net/http"
+	"time"
+)
+
+// probeTimeout bounds each metadata request, so detection is quick on nodes
+// outside the cloud. Tests shorten it
+var probeTimeout = 2 * time.Second
+
+// Metadata is what a cloud provider's metadata service tells us about a node
+type Metadata struct {
+	// Provider is the kubernetes --cloud-provider name
+	Provider  string
+	Hostname  string
+	Domain    string
+	Region    string
+	Zone      string
+	IPAddress string
+	// IPAddress6 is empty if the node has no IPv6 address
+	IPAddress6 string
+}
+
+// Probe detects a cloud provider using its metadata service
+type Probe interface {
+	// Name of the cloud provider
+	Name() string
+	// Available reports whether the metadata service can be reached
+	Available() bool
+	// Metadata reads the node's metadata
+	Metadata() (Metadata, error)
+}
+
+// DefaultProbes returns probes for all supported cloud providers using their
+// standard metadata endpoints
+func DefaultProbes() []Probe {
+	return []Probe{
+		&AWS{},
+		&GCE{Endpoint: GCEEndpoint},
+		&Azure{Endpoint: AzureEndpoint},
+	}
+}
+
+// Detect returns the metadata from the first available probe. ok is false if
+// the node isn't running in any of the clouds
+func Detect(probes []Probe) (md Metadata, ok bool, err error) {
+	for _, p := range probes {
+		if !p.Available() {
+			continue
+		}
+		md, err := p.Metadata()
+		if err != nil {
+			return Metadata{}, false, err
+		}
+		return md, true, nil
+	}
+	return Metadata{}, false, nil
+}
+
+func httpClient() *http.Client {
+	return &http.Client{Timeout: probeTimeout}
+}
@@ -0,0 +1,77 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hang blocks a metadata request until the client gives up
+func hang(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+// withProbeTimeout shortens the metadata request timeout for a test
+func withProbeTimeout(timeout time.Duration) func() {
+	old := probeTimeout
+	probeTimeout = timeout
+	return func() { probeTimeout = old }
+}
+
+func TestTimeout(t *testing.T) {
+	defer withProbeTimeout(50 * time.Millisecond)()
+
+	server := httptest.NewServer(http.HandlerFunc(hang))
+	defer server.Close()
+
+	probes := []Probe{&GCE{Endpoint: server.URL}, &Azure{Endpoint: server.URL}}
+	for _, p := range probes {
+		start := time.Now()
+		if p.Available() {
+			t.Errorf("%s: expected a hanging metadata service to be unavailable", p.Name())
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: probe took %s", p.Name(), elapsed)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	azure := httptest.NewServer(azureHandler(azureMetadata))
+	defer azure.Close()
+	// answers, but isn't GCE
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+
+	md, ok, err := Detect([]Probe{&GCE{Endpoint: other.URL}, &Azure{Endpoint: azure.URL}})
+	if err != nil || !ok {
+		t.Fatalf("got ok %v, error %v", ok, err)
+	}
+	if md.Provider != "azure" {
+		t.Errorf("got provider %s, want azure", md.Provider)
+	}
+
+	if _, ok, err := Detect([]Probe{&GCE{Endpoint: other.URL}, &Azure{Endpoint: other.URL}}); ok || err != nil {
+		t.Errorf("got ok %v, error %v outside any cloud", ok, err)
+	}
+}
+
+// metadataServer serves fixed paths, and 404 for anything else
+func metadataServer(header http.Header, paths map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		body, ok := paths[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}
+}
@@ -0,0 +1,102 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// GCEEndpoint is the address of the GCE metadata server
+const GCEEndpoint = "http://metadata.google.internal"
+
+// GCE probes the Google Compute Engine metadata server
+type GCE struct {
+	Endpoint string
+}
+
+// Name of the cloud provider
+func (g *GCE) Name() string {
+	return "gce"
+}
+
+func (g *GCE) get(p string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", g.Endpoint+"/computeMetadata/v1/"+p, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return httpClient().Do(req)
+}
+
+func (g *GCE) getString(p string) (string, error) {
+	resp, err := g.get(p)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error reading GCE metadata %s: %s", p, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// Available reports whether the GCE metadata server can be reached. The
+// server identifies itself with a Metadata-Flavor header
+func (g *GCE) Available() bool {
+	resp, err := g.get("")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.Header.Get("Metadata-Flavor") == "Google"
+}
+
+// Metadata reads the instance metadata
+func (g *GCE) Metadata() (Metadata, error) {
+	fqdn, err := g.getString("instance/hostname")
+	if err != nil {
+		return Metadata{}, err
+	}
+	// returned as projects/<number>/zones/<zone>
+	zone, err := g.getString("instance/zone")
+	if err != nil {
+		return Metadata{}, err
+	}
+	ip, err := g.getString("instance/network-interfaces/0/ip")
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	md := Metadata{
+		Provider:  "gce",
+		Zone:      path.Base(zone),
+		IPAddress: ip,
+	}
+
+	// only dual-stack interfaces have IPv6 addresses, one per line
+	if ip6s, err := g.getString("instance/network-interfaces/0/ipv6s"); err == nil && ip6s != "" {
+		md.IPAddress6 = strings.Split(ip6s, "\n")[0]
+	}
+
+	// zones are named <region>-<letter>
+	if i := strings.LastIndex(md.Zone, "-"); i > 0 {
+		md.Region = md.Zone[:i]
+	}
+
+	// the GCE cloud provider expects nodes to be named after the instance
+	splitHostname := strings.SplitN(fqdn, ".", 2)
+	md.Hostname = splitHostname[0]
+	if len(splitHostname) == 2 {
+		md.Domain = splitHostname[1]
+	}
+
+	return md, nil
+}
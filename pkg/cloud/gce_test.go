@@ -0,0 +1,97 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var gceMetadata = map[string]string{
+	"/computeMetadata/v1/":                                    "instance/\nproject/\n",
+	"/computeMetadata/v1/instance/hostname":                   "node1.c.project.internal",
+	"/computeMetadata/v1/instance/zone":                       "projects/123456/zones/us-central1-b",
+	"/computeMetadata/v1/instance/network-interfaces/0/ip":    "10.128.0.2",
+	"/computeMetadata/v1/instance/network-interfaces/0/ipv6s": "2600:1900:4000::2\n2600:1900:4000::3\n",
+}
+
+// gceHandler serves GCE metadata, which is only returned to requests with
+// the Metadata-Flavor header
+func gceHandler(paths map[string]string) http.HandlerFunc {
+	serve := metadataServer(http.Header{"Metadata-Flavor": {"Google"}}, paths)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor header", http.StatusForbidden)
+			return
+		}
+		serve(w, r)
+	}
+}
+
+func TestGCE(t *testing.T) {
+	server := httptest.NewServer(gceHandler(gceMetadata))
+	defer server.Close()
+
+	g := &GCE{Endpoint: server.URL}
+	if !g.Available() {
+		t.Fatal("expected GCE to be detected")
+	}
+
+	md, err := g.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Metadata{
+		Provider:   "gce",
+		Hostname:   "node1",
+		Domain:     "c.project.internal",
+		Region:     "us-central1",
+		Zone:       "us-central1-b",
+		IPAddress:  "10.128.0.2",
+		IPAddress6: "2600:1900:4000::2",
+	}
+	if md != want {
+		t.Errorf("got %+v, want %+v", md, want)
+	}
+}
+
+func TestGCEWithoutIPv6(t *testing.T) {
+	paths := map[string]string{}
+	for p, body := range gceMetadata {
+		paths[p] = body
+	}
+	delete(paths, "/computeMetadata/v1/instance/network-interfaces/0/ipv6s")
+
+	server := httptest.NewServer(gceHandler(paths))
+	defer server.Close()
+
+	md, err := (&GCE{Endpoint: server.URL}).Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.IPAddress6 != "" {
+		t.Errorf("unexpected IPv6 address %s", md.IPAddress6)
+	}
+}
+
+func TestGCENotMatching(t *testing.T) {
+	// another metadata service at the same address, without the header
+	server := httptest.NewServer(metadataServer(nil, gceMetadata))
+	defer server.Close()
+
+	if (&GCE{Endpoint: server.URL}).Available() {
+		t.Error("expected a response without Metadata-Flavor not to be GCE")
+	}
+}
+
+func TestGCEMissingMetadata(t *testing.T) {
+	server := httptest.NewServer(gceHandler(map[string]string{"/computeMetadata/v1/": ""}))
+	defer server.Close()
+
+	g := &GCE{Endpoint: server.URL}
+	if !g.Available() {
+		t.Fatal("expected GCE to be detected")
+	}
+	if _, err := g.Metadata(); err == nil {
+		t.Error("expected an error for missing metadata")
+	}
+}
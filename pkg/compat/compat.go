@@ -0,0 +1,236 @@
+package compat
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// argChange is a control plane arg that was renamed or removed in a
+// kubernetes release
+type argChange struct {
+	component string
+	arg       string
+	// replacement is the new name of the arg, or empty if it was removed
+	replacement string
+	// since is the first minor release of kubernetes 1.x with the change
+	since int
+}
+
+// pluginRemoval is an admission plugin that was removed in a kubernetes
+// release
+type pluginRemoval struct {
+	plugin string
+	since  int
+}
+
+// fieldAddition is a field of a kind of kubeadm document that kubeadm only
+// takes from a kubernetes release on
+type fieldAddition struct {
+	kind  string
+	field string
+	since int
+}
+
+// argChanges are applied in order, so an arg can be renamed and then removed
+var argChanges = []argChange{
+	{component: "apiserver", arg: "admission-control", replacement: "enable-admission-plugins", since: 10},
+	{component: "apiserver", arg: "repair-malformed-updates", since: 14},
+	{component: "controller-manager", arg: "address", replacement: "bind-address", since: 12},
+	{component: "scheduler", arg: "address", replacement: "bind-address", since: 12},
+}
+
+// pluginRemovals are dropped from the admission plugin args
+var pluginRemovals = []pluginRemoval{
+	{plugin: "Initializers", since: 14},
+	{plugin: "DenyEscalatingExec", since: 18},
+	{plugin: "PodSecurityPolicy", since: 25},
+	{plugin: "SecurityContextDeny", since: 30},
+	// deprecated since 1.8, removed with the in-tree cloud providers
+	{plugin: "PersistentVolumeLabel", since: 31},
+}
+
+// fieldAdditions are dropped for releases before they were added
+var fieldAdditions = []fieldAddition{
+	{kind: "MasterConfiguration", field: "kubeProxy", since: 9},
+	{kind: "MasterConfiguration", field: "kubeletConfiguration", since: 10},
+	{kind: "MasterConfiguration", field: "tokenUsages", since: 9},
+	{kind: "MasterConfiguration", field: "tokenGroups", since: 9},
+}
+
+// dualStackSince and dualStackGA are the minor releases that added dual-stack
+// networking, behind the IPv6DualStack feature gate until it went GA
+const (
+	dualStackSince = 16
+	dualStackGA    = 23
+)
+
+// pluginArgs are the args that list admission plugins
+var pluginArgs = []string{"admission-control", "enable-admission-plugins"}
+
+// argFields are where each kind of kubeadm document keeps the args of each
+// component
+var argFields = map[string]map[string][]string{
+	"MasterConfiguration": {
+		"apiserver":          {"apiServerExtraArgs"},
+		"controller-manager": {"controllerManagerExtraArgs"},
+		"scheduler":          {"schedulerExtraArgs"},
+	},
+	"ClusterConfiguration": {
+		"apiserver":          {"apiServer", "extraArgs"},
+		"controller-manager": {"controllerManager", "extraArgs"},
+		"scheduler":          {"scheduler", "extraArgs"},
+	},
+}
+
+// versionRe matches a kubernetes version such as v1.18.20 or 1.18
+var versionRe = regexp.MustCompile(`^v?1\.([0-9]+)(\.[0-9]+)?([-+].*)?$`)
+
+// Minor returns the minor release of a kubernetes 1.x version
+func Minor(version string) (int, error) {
+	m := versionRe.FindStringSubmatch(version)
+	if m == nil {
+		return 0, fmt.Errorf("invalid kubernetes version %q, must be like v1.18.20", version)
+	}
+	return strconv.Atoi(m[1])
+}
+
+// Translate rewrites the control plane args of a kubeadm document for its
+// kubernetesVersion, renaming and dropping args, admission plugins and fields
+// that version doesn't have, and enables dual-stack networking where it is behind
+// a feature gate. It returns a warning for each change, and no warnings if
+// the document was left as it was
+func Translate(doc map[string]interface{}) ([]string, error) {
+	kind, _ := doc["kind"].(string)
+	fields, ok := argFields[kind]
+	if !ok {
+		return nil, nil
+	}
+
+	version, _ := doc["kubernetesVersion"].(string)
+	if version == "" {
+		return nil, nil
+	}
+	minor, err := Minor(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	for _, addition := range fieldAdditions {
+		if _, ok := doc[addition.field]; addition.kind != kind || minor >= addition.since || !ok {
+			continue
+		}
+		delete(doc, addition.field)
+		warnings = append(warnings, fmt.Sprintf("Dropping %s, which kubeadm added in kubernetes 1.%d", addition.field, addition.since))
+	}
+
+	for _, component := range sortedKeys(fields) {
+		args := lookup(doc, fields[component])
+		if args == nil {
+			continue
+		}
+
+		for _, change := range argChanges {
+			value, ok := args[change.arg]
+			if change.component != component || minor < change.since || !ok {
+				continue
+			}
+			delete(args, change.arg)
+			if change.replacement == "" {
+				warnings = append(warnings, fmt.Sprintf("Dropping %s arg %s, which kubernetes %s doesn't support", component, change.arg, version))
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("Renaming %s arg %s to %s for kubernetes %s", component, change.arg, change.replacement, version))
+			if _, ok := args[change.replacement]; !ok {
+				args[change.replacement] = value
+			}
+		}
+
+		for _, arg := range pluginArgs {
+			value, ok := args[arg].(string)
+			if !ok {
+				continue
+			}
+			var kept []string
+			for _, plugin := range strings.Split(value, ",") {
+				if since := removedSince(plugin); since > 0 && minor >= since {
+					warnings = append(warnings, fmt.Sprintf("Dropping admission plugin %s, which was removed in kubernetes 1.%d", plugin, since))
+					continue
+				}
+				kept = append(kept, plugin)
+			}
+			if len(kept) == 0 {
+				delete(args, arg)
+				continue
+			}
+			args[arg] = strings.Join(kept, ",")
+		}
+	}
+
+	if kind == "ClusterConfiguration" && dualStack(doc) {
+		if minor < dualStackSince {
+			return nil, fmt.Errorf("dual-stack networking needs kubernetes 1.%d or newer, not %s", dualStackSince, version)
+		}
+		if minor < dualStackGA {
+			gates, _ := doc["featureGates"].(map[string]interface{})
+			if gates == nil {
+				gates = map[string]interface{}{}
+				doc["featureGates"] = gates
+			}
+			if _, ok := gates["IPv6DualStack"]; !ok {
+				gates["IPv6DualStack"] = true
+				warnings = append(warnings, fmt.Sprintf("Enabling feature gate IPv6DualStack for dual-stack networking on kubernetes %s", version))
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// dualStack returns true if a ClusterConfiguration has a dual-stack pod or
+// service subnet
+func dualStack(doc map[string]interface{}) bool {
+	networking := lookup(doc, []string{"networking"})
+	for _, field := range []string{"podSubnet", "serviceSubnet"} {
+		if subnet, ok := networking[field].(string); ok && strings.Contains(subnet, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// removedSince returns the minor release an admission plugin was removed in,
+// or 0 if it hasn't been
+func removedSince(plugin string) int {
+	for _, removal := range pluginRemovals {
+		if removal.plugin == plugin {
+			return removal.since
+		}
+	}
+	return 0
+}
+
+// lookup returns the object at a path of fields in a document, or nil
+func lookup(doc map[string]interface{}, path []string) map[string]interface{} {
+	m := doc
+	for _, field := range path {
+		next, ok := m[field].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	return m
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
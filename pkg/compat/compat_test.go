@@ -0,0 +1,278 @@
+package compat
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMinor(t *testing.T) {
+	tests := map[string]int{
+		"v1.8.4":        8,
+		"1.18":          18,
+		"v1.22.17":      22,
+		"v1.30.0-rc.1":  30,
+		"v1.10.3+build": 10,
+	}
+	for version, want := range tests {
+		if got, err := Minor(version); err != nil || got != want {
+			t.Errorf("%s: got %d, %v, want %d", version, got, err, want)
+		}
+	}
+
+	for _, version := range []string{"", "latest", "v2.0.0", "v1.x"} {
+		if _, err := Minor(version); err == nil {
+			t.Errorf("%s: expected an error", version)
+		}
+	}
+}
+
+// masterConfig is a v1alpha1 document with control plane args
+func masterConfig(version string, apiServer, controllerManager, scheduler map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":                       "MasterConfiguration",
+		"kubernetesVersion":          version,
+		"apiServerExtraArgs":         apiServer,
+		"controllerManagerExtraArgs": controllerManager,
+		"schedulerExtraArgs":         scheduler,
+	}
+}
+
+// clusterConfig is a v1beta document with control plane args
+func clusterConfig(version string, apiServer, controllerManager, scheduler map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":              "ClusterConfiguration",
+		"kubernetesVersion": version,
+		"apiServer":         map[string]interface{}{"extraArgs": apiServer},
+		"controllerManager": map[string]interface{}{"extraArgs": controllerManager},
+		"scheduler":         map[string]interface{}{"extraArgs": scheduler},
+	}
+}
+
+func TestTranslateArgs(t *testing.T) {
+	args := func() (map[string]interface{}, map[string]interface{}, map[string]interface{}) {
+		return map[string]interface{}{"admission-control": "NodeRestriction", "repair-malformed-updates": "false", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0"}
+	}
+
+	tests := []struct {
+		version           string
+		apiServer         map[string]interface{}
+		controllerManager map[string]interface{}
+		scheduler         map[string]interface{}
+		warnings          int
+	}{
+		{
+			"v1.9.11",
+			map[string]interface{}{"admission-control": "NodeRestriction", "repair-malformed-updates": "false", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0"},
+			0,
+		},
+		{
+			"v1.10.0",
+			map[string]interface{}{"enable-admission-plugins": "NodeRestriction", "repair-malformed-updates": "false", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0", "profiling": "false"},
+			map[string]interface{}{"address": "0.0.0.0"},
+			1,
+		},
+		{
+			"v1.12.10",
+			map[string]interface{}{"enable-admission-plugins": "NodeRestriction", "repair-malformed-updates": "false", "profiling": "false"},
+			map[string]interface{}{"bind-address": "0.0.0.0", "profiling": "false"},
+			map[string]interface{}{"bind-address": "0.0.0.0"},
+			3,
+		},
+		{
+			"v1.14.0",
+			map[string]interface{}{"enable-admission-plugins": "NodeRestriction", "profiling": "false"},
+			map[string]interface{}{"bind-address": "0.0.0.0", "profiling": "false"},
+			map[string]interface{}{"bind-address": "0.0.0.0"},
+			4,
+		},
+	}
+
+	for _, test := range tests {
+		for _, config := range []func(string, map[string]interface{}, map[string]interface{}, map[string]interface{}) map[string]interface{}{masterConfig, clusterConfig} {
+			apiServer, controllerManager, scheduler := args()
+			doc := config(test.version, apiServer, controllerManager, scheduler)
+			warnings, err := Translate(doc)
+			if err != nil {
+				t.Fatalf("%s: %v", test.version, err)
+			}
+			want := config(test.version, test.apiServer, test.controllerManager, test.scheduler)
+			if !reflect.DeepEqual(doc, want) {
+				t.Errorf("%s %s: got %v, want %v", test.version, doc["kind"], doc, want)
+			}
+			if len(warnings) != test.warnings {
+				t.Errorf("%s %s: got warnings %q, want %d", test.version, doc["kind"], warnings, test.warnings)
+			}
+		}
+	}
+}
+
+func TestTranslateKeepsReplacement(t *testing.T) {
+	doc := clusterConfig("v1.18.20",
+		map[string]interface{}{"admission-control": "AlwaysAdmit", "enable-admission-plugins": "NodeRestriction"},
+		nil, nil)
+
+	if _, err := Translate(doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"enable-admission-plugins": "NodeRestriction"}
+	if args := lookup(doc, []string{"apiServer", "extraArgs"}); !reflect.DeepEqual(args, want) {
+		t.Errorf("got %v, want %v", args, want)
+	}
+}
+
+func TestTranslatePlugins(t *testing.T) {
+	const plugins = "Initializers,NodeRestriction,DenyEscalatingExec,PodSecurityPolicy,SecurityContextDeny,PersistentVolumeLabel"
+
+	tests := map[string]string{
+		"v1.13.12": plugins,
+		"v1.14.0":  "NodeRestriction,DenyEscalatingExec,PodSecurityPolicy,SecurityContextDeny,PersistentVolumeLabel",
+		"v1.18.20": "NodeRestriction,PodSecurityPolicy,SecurityContextDeny,PersistentVolumeLabel",
+		"v1.25.0":  "NodeRestriction,SecurityContextDeny,PersistentVolumeLabel",
+		"v1.30.0":  "NodeRestriction,PersistentVolumeLabel",
+		"v1.31.0":  "NodeRestriction",
+	}
+
+	for version, want := range tests {
+		doc := clusterConfig(version, map[string]interface{}{"enable-admission-plugins": plugins}, nil, nil)
+		warnings, err := Translate(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lookup(doc, []string{"apiServer", "extraArgs"})["enable-admission-plugins"]; got != want {
+			t.Errorf("%s: got plugins %v, want %s", version, got, want)
+		}
+		if dropped := strings.Count(plugins, ",") - strings.Count(want, ","); len(warnings) != dropped {
+			t.Errorf("%s: got warnings %q, want %d", version, warnings, dropped)
+		}
+	}
+}
+
+func TestTranslateDropsEmptyPlugins(t *testing.T) {
+	doc := masterConfig("v1.14.0", map[string]interface{}{"admission-control": "Initializers"}, nil, nil)
+	if _, err := Translate(doc); err != nil {
+		t.Fatal(err)
+	}
+	if args := doc["apiServerExtraArgs"].(map[string]interface{}); len(args) != 0 {
+		t.Errorf("expected no admission plugin args, got %v", args)
+	}
+}
+
+func TestTranslateUnchanged(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"other kind":         {"kind": "InitConfiguration", "kubernetesVersion": "v1.18.20"},
+		"no version":         {"kind": "ClusterConfiguration", "apiServer": map[string]interface{}{"extraArgs": map[string]interface{}{"admission-control": "NodeRestriction"}}},
+		"no args":            {"kind": "ClusterConfiguration", "kubernetesVersion": "v1.18.20"},
+		"args of other type": {"kind": "MasterConfiguration", "kubernetesVersion": "v1.18.20", "apiServerExtraArgs": "admission-control=NodeRestriction"},
+	}
+
+	for name, doc := range tests {
+		warnings, err := Translate(doc)
+		if err != nil || len(warnings) != 0 {
+			t.Errorf("%s: got warnings %q, error %v", name, warnings, err)
+		}
+	}
+
+	if _, err := Translate(map[string]interface{}{"kind": "ClusterConfiguration", "kubernetesVersion": "latest"}); err == nil {
+		t.Error("expected an error for an invalid kubernetes version")
+	}
+}
+
+func TestTranslateFields(t *testing.T) {
+	fields := func(version string) map[string]interface{} {
+		return map[string]interface{}{
+			"kind":                 "MasterConfiguration",
+			"kubernetesVersion":    version,
+			"tokenTTL":             "0",
+			"tokenUsages":          []interface{}{"signing", "authentication"},
+			"tokenGroups":          []interface{}{"system:bootstrappers:kubeadm:default-node-token"},
+			"kubeProxy":            map[string]interface{}{},
+			"kubeletConfiguration": map[string]interface{}{},
+		}
+	}
+
+	tests := map[string][]string{
+		"v1.8.4":   {"kind", "kubernetesVersion", "tokenTTL"},
+		"v1.9.11":  {"kind", "kubeProxy", "kubernetesVersion", "tokenGroups", "tokenTTL", "tokenUsages"},
+		"v1.10.13": {"kind", "kubeProxy", "kubeletConfiguration", "kubernetesVersion", "tokenGroups", "tokenTTL", "tokenUsages"},
+	}
+
+	for version, want := range tests {
+		doc := fields(version)
+		warnings, err := Translate(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for field := range doc {
+			got = append(got, field)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got fields %v, want %v", version, got, want)
+		}
+		if len(warnings) != len(fields(version))-len(want) {
+			t.Errorf("%s: got warnings %q", version, warnings)
+		}
+	}
+
+	// only MasterConfiguration has them
+	doc := map[string]interface{}{"kind": "ClusterConfiguration", "kubernetesVersion": "v1.8.4", "kubeProxy": map[string]interface{}{}}
+	if _, err := Translate(doc); err != nil || doc["kubeProxy"] == nil {
+		t.Errorf("unexpected change to %v, error %v", doc, err)
+	}
+}
+
+func TestTranslateDualStack(t *testing.T) {
+	dualStack := func(version string, gates map[string]interface{}) map[string]interface{} {
+		doc := map[string]interface{}{
+			"kind":              "ClusterConfiguration",
+			"kubernetesVersion": version,
+			"networking":        map[string]interface{}{"podSubnet": "10.244.0.0/16,fd00:10:244::/56"},
+		}
+		if gates != nil {
+			doc["featureGates"] = gates
+		}
+		return doc
+	}
+
+	tests := []struct {
+		version string
+		gates   map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{"v1.16.0", nil, map[string]interface{}{"IPv6DualStack": true}},
+		{"v1.22.17", map[string]interface{}{"EphemeralContainers": true}, map[string]interface{}{"EphemeralContainers": true, "IPv6DualStack": true}},
+		// an explicit gate is left alone
+		{"v1.20.0", map[string]interface{}{"IPv6DualStack": false}, map[string]interface{}{"IPv6DualStack": false}},
+		{"v1.23.0", nil, nil},
+	}
+
+	for _, test := range tests {
+		doc := dualStack(test.version, test.gates)
+		if _, err := Translate(doc); err != nil {
+			t.Errorf("%s: %v", test.version, err)
+			continue
+		}
+		gates, _ := doc["featureGates"].(map[string]interface{})
+		if !reflect.DeepEqual(gates, test.want) {
+			t.Errorf("%s: got feature gates %v, want %v", test.version, gates, test.want)
+		}
+	}
+
+	if _, err := Translate(dualStack("v1.15.12", nil)); err == nil || !strings.Contains(err.Error(), "dual-stack networking needs kubernetes 1.16") {
+		t.Errorf("unexpected error %v before 1.16", err)
+	}
+
+	// single-stack clusters don't get the gate
+	doc := map[string]interface{}{"kind": "ClusterConfiguration", "kubernetesVersion": "v1.20.0", "networking": map[string]interface{}{"podSubnet": "10.244.0.0/16"}}
+	if _, err := Translate(doc); err != nil || doc["featureGates"] != nil {
+		t.Errorf("unexpected feature gates %v, error %v", doc["featureGates"], err)
+	}
+}
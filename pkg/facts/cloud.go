@@ -0,0 +1,72 @@
+package facts
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/apptio/kubeadm-bootstrap/pkg/cloud"
+)
+
+// Cloud reads facts from the metadata service of the cloud the node is
+// running in. The region is used as the datacenter. Outside of a cloud it
+// provides no facts
+type Cloud struct {
+	// Probes are the clouds to check for, in order. Defaults to all
+	// supported clouds
+	Probes []cloud.Probe
+
+	facts  map[string]string
+	loaded bool
+}
+
+// Name of the provider
+func (c *Cloud) Name() string {
+	return "cloud"
+}
+
+func (c *Cloud) load() error {
+	if c.loaded {
+		return nil
+	}
+	c.loaded = true
+	c.facts = map[string]string{}
+
+	probes := c.Probes
+	if probes == nil {
+		probes = cloud.DefaultProbes()
+	}
+
+	md, ok, err := cloud.Detect(probes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Info("Not running in a cloud")
+		return nil
+	}
+
+	log.Info("Running in ", md.Provider)
+
+	for name, value := range map[string]string{
+		CloudProvider: md.Provider,
+		Datacenter:    md.Region,
+		Hostname:      md.Hostname,
+		Domain:        md.Domain,
+		IPAddress:     md.IPAddress,
+		IPAddress6:    md.IPAddress6,
+	} {
+		if value != "" {
+			c.facts[name] = value
+		}
+	}
+
+	return nil
+}
+
+// Fact returns the value of a fact from the cloud metadata service
+func (c *Cloud) Fact(name string) (string, bool, error) {
+	if err := c.load(); err != nil {
+		return "", false, err
+	}
+	value, ok := c.facts[name]
+	return value, ok, nil
+}
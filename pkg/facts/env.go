@@ -0,0 +1,23 @@
+package facts
+
+import (
+	"os"
+	"strings"
+)
+
+// Env reads facts from environment variables named Prefix + the upper case
+// fact name, e.g. KUBEADM_BOOTSTRAP_DATACENTER
+type Env struct {
+	Prefix string
+}
+
+// Name of the provider
+func (e *Env) Name() string {
+	return "env"
+}
+
+// Fact returns the value of a fact from the environment
+func (e *Env) Fact(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(e.Prefix + strings.ToUpper(name))
+	return value, ok, nil
+}
@@ -0,0 +1,93 @@
+package facts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// DefaultFacterFacts maps facts to the puppet facts we use by default
+var DefaultFacterFacts = map[string]string{
+	Datacenter: "datacenter",
+}
+
+// Facter reads facts from puppet's facter, using facter -p --json so
+// structured facts can be used. If facter isn't installed it provides no facts
+type Facter struct {
+	// Facts maps our fact names to facter facts, e.g. ipaddress: networking.ip
+	Facts map[string]string
+
+	facts  map[string]interface{}
+	loaded bool
+}
+
+// Name of the provider
+func (f *Facter) Name() string {
+	return "facter"
+}
+
+func (f *Facter) load() error {
+	if f.loaded {
+		return nil
+	}
+	f.loaded = true
+
+	if _, err := exec.LookPath("facter"); err != nil {
+		log.Debug("facter not found, skipping facter facts")
+		return nil
+	}
+
+	// only ask for the top level facts we need
+	args := []string{"-p", "--json"}
+	seen := map[string]bool{}
+	for _, path := range f.Facts {
+		top := strings.Split(path, ".")[0]
+		if !seen[top] {
+			seen[top] = true
+			args = append(args, top)
+		}
+	}
+
+	out, err := exec.Command("facter", args...).Output()
+	if err != nil {
+		return fmt.Errorf("error running facter: %v", err)
+	}
+
+	return json.Unmarshal(out, &f.facts)
+}
+
+// Fact returns the value of a fact from facter
+func (f *Facter) Fact(name string) (string, bool, error) {
+	path, ok := f.Facts[name]
+	if !ok {
+		return "", false, nil
+	}
+
+	if err := f.load(); err != nil {
+		return "", false, err
+	}
+
+	var value interface{} = f.facts
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false, nil
+		}
+		value = m[key]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		// facter returns empty strings for unresolved custom facts
+		return v, v != "", nil
+	case map[string]interface{}, []interface{}:
+		return "", false, fmt.Errorf("fact %s is structured, address a value inside it", path)
+	default:
+		return fmt.Sprint(v), true, nil
+	}
+}
@@ -0,0 +1,109 @@
+package facts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeFacter puts a facter on the PATH that prints facts as JSON and records
+// its args. It returns a function that reads the args and restores the PATH
+func fakeFacter(t *testing.T, json string) func() string {
+	dir, err := ioutil.TempDir("", "facter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the PATH only has facter, so the script only uses shell builtins
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\nprintf '%s' '" + json + "'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "facter"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	return func() string {
+		os.Setenv("PATH", path)
+		args, _ := ioutil.ReadFile(filepath.Join(dir, "args"))
+		os.RemoveAll(dir)
+		return strings.TrimSpace(string(args))
+	}
+}
+
+func TestFacter(t *testing.T) {
+	done := fakeFacter(t, `{
+  "datacenter": "dc1",
+  "role": "",
+  "networking": {"ip": "10.0.0.1", "ip6": "fd00::1", "mtu": 9001, "interfaces": {"eth0": {}}},
+  "os": {"release": {"major": "7"}},
+  "disks": ["sda"]
+}`)
+
+	f := &Facter{Facts: map[string]string{
+		Datacenter:    "datacenter",
+		IPAddress:     "networking.ip",
+		IPAddress6:    "networking.ip6",
+		Domain:        "role",
+		Hostname:      "networking.hostname",
+		CloudProvider: "os.release.major",
+		"mtu":         "networking.mtu",
+		"interfaces":  "networking.interfaces",
+		"disks":       "disks",
+		"deep":        "datacenter.name",
+	}}
+
+	tests := map[string]struct {
+		value string
+		ok    bool
+		err   string
+	}{
+		Datacenter:    {"dc1", true, ""},
+		IPAddress:     {"10.0.0.1", true, ""},
+		IPAddress6:    {"fd00::1", true, ""},
+		CloudProvider: {"7", true, ""},
+		"mtu":         {"9001", true, ""},
+		// facter's empty strings are unresolved facts
+		Domain: {"", false, ""},
+		// missing facts, and paths through values that aren't structured
+		Hostname: {"", false, ""},
+		"deep":   {"", false, ""},
+		// facts that aren't mapped
+		"region": {"", false, ""},
+		// structured facts must be addressed inside
+		"interfaces": {"", false, "fact networking.interfaces is structured, address a value inside it"},
+		"disks":      {"", false, "fact disks is structured, address a value inside it"},
+	}
+	for name, want := range tests {
+		value, ok, err := f.Fact(name)
+		if value != want.value || ok != want.ok || (err == nil) != (want.err == "") || (err != nil && err.Error() != want.err) {
+			t.Errorf("%s: got %q, %t, %v, want %q, %t, %s", name, value, ok, err, want.value, want.ok, want.err)
+		}
+	}
+
+	// facter is only asked for the top level facts that are mapped, once
+	args := strings.Fields(done())
+	if len(args) != 7 || args[0] != "-p" || args[1] != "--json" {
+		t.Errorf("unexpected facter args %v", args)
+	}
+	for _, top := range []string{"datacenter", "networking", "role", "os", "disks"} {
+		if !strings.Contains(" "+strings.Join(args, " ")+" ", " "+top+" ") {
+			t.Errorf("facter args %v don't include %s", args, top)
+		}
+	}
+}
+
+func TestFacterErrors(t *testing.T) {
+	done := fakeFacter(t, "not json")
+	defer done()
+
+	if _, _, err := (&Facter{Facts: DefaultFacterFacts}).Fact(Datacenter); err == nil {
+		t.Error("expected an error for invalid facter output")
+	}
+
+	// without facter there are no facts
+	os.Setenv("PATH", "")
+	if _, ok, err := (&Facter{Facts: DefaultFacterFacts}).Fact(Datacenter); err != nil || ok {
+		t.Errorf("got %t, %v without facter", ok, err)
+	}
+}
@@ -0,0 +1,111 @@
+package facts
+
+import (
+	"fmt"
+	"strings"
+
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+)
+
+// The facts kubeadm-bootstrap detects about a node
+const (
+	Datacenter    = "datacenter"
+	Hostname      = "hostname"
+	Domain        = "domain"
+	IPAddress     = "ipaddress"
+	IPAddress6    = "ipaddress6"
+	CloudProvider = "cloudprovider"
+)
+
+// DefaultOrder is the default precedence of fact providers, highest first
+const DefaultOrder = "env,file,facter,cloud,system"
+
+// Provider looks up facts about the node it's running on
+type Provider interface {
+	// Name identifies the provider when reporting where a fact came from
+	Name() string
+	// Fact returns the value of the named fact. ok is false if the provider
+	// doesn't know the fact
+	Fact(name string) (value string, ok bool, err error)
+}
+
+// Value is a detected fact and the name of the provider that supplied it
+type Value struct {
+	Value  string
+	Source string
+}
+
+// Options configures the built in providers
+type Options struct {
+	// FactsFile is the path to a static YAML or JSON facts file
+	FactsFile string
+	// FacterFacts maps our fact names to facter facts. Structured facts are
+	// addressed with dots, e.g. networking.ip
+	FacterFacts map[string]string
+	// Address picks the node's addresses from its interfaces. If set, the
+	// interface provider is added ahead of every other provider
+	Address n.AddressSelector
+	// ProbeTarget is the address the system provider finds the route to
+	ProbeTarget string
+}
+
+// NewProvider creates one of the built in providers by name
+func NewProvider(name string, opts Options) (Provider, error) {
+	switch name {
+	case "env":
+		return &Env{Prefix: "KUBEADM_BOOTSTRAP_"}, nil
+	case "file":
+		return &File{Path: opts.FactsFile}, nil
+	case "facter":
+		return &Facter{Facts: opts.FacterFacts}, nil
+	case "cloud":
+		return &Cloud{}, nil
+	case "system":
+		return &System{ProbeTarget: opts.ProbeTarget}, nil
+	case "interface":
+		return &Interface{Selector: opts.Address}, nil
+	}
+	return nil, fmt.Errorf("unknown fact provider %q", name)
+}
+
+// Chain queries providers in order of precedence. The first provider that
+// knows a fact supplies it
+type Chain []Provider
+
+// NewChain creates a chain from a comma separated list of provider names. An
+// explicit address selection takes precedence, unless the interface provider
+// is listed
+func NewChain(order string, opts Options) (Chain, error) {
+	var chain Chain
+	names := strings.Split(order, ",")
+	if opts.Address.Set() && !strings.Contains(","+order+",", ",interface,") {
+		names = append([]string{"interface"}, names...)
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, err := NewProvider(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// Lookup returns the value of a fact from the first provider that knows it.
+// ok is false if no provider does
+func (c Chain) Lookup(name string) (v Value, ok bool, err error) {
+	for _, p := range c {
+		value, ok, err := p.Fact(name)
+		if err != nil {
+			return Value{}, false, fmt.Errorf("%s: %v", p.Name(), err)
+		}
+		if ok {
+			return Value{Value: value, Source: p.Name()}, true, nil
+		}
+	}
+	return Value{}, false, nil
+}
@@ -0,0 +1,175 @@
+package facts
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// static is a provider of fixed facts
+type static struct {
+	name  string
+	facts map[string]string
+	err   error
+}
+
+func (s static) Name() string {
+	return s.name
+}
+
+func (s static) Fact(name string) (string, bool, error) {
+	value, ok := s.facts[name]
+	return value, ok, s.err
+}
+
+func TestChainLookup(t *testing.T) {
+	chain := Chain{
+		static{name: "first", facts: map[string]string{Datacenter: "dc1", Domain: ""}},
+		static{name: "second", facts: map[string]string{Datacenter: "dc2", Hostname: "node1"}},
+		static{name: "third", facts: map[string]string{Hostname: "node2", IPAddress: "10.0.0.1"}},
+	}
+
+	tests := map[string]Value{
+		// the first provider that knows a fact supplies it
+		Datacenter: {Value: "dc1", Source: "first"},
+		Hostname:   {Value: "node1", Source: "second"},
+		IPAddress:  {Value: "10.0.0.1", Source: "third"},
+		// even if it's empty
+		Domain: {Value: "", Source: "first"},
+	}
+	for name, want := range tests {
+		got, ok, err := chain.Lookup(name)
+		if err != nil || !ok || got != want {
+			t.Errorf("%s: got %+v, %t, %v, want %+v", name, got, ok, err, want)
+		}
+	}
+
+	if got, ok, err := chain.Lookup(CloudProvider); err != nil || ok || got != (Value{}) {
+		t.Errorf("got %+v, %t, %v for an unknown fact", got, ok, err)
+	}
+
+	// errors stop the lookup, and say which provider failed
+	chain = Chain{
+		static{name: "env"},
+		static{name: "broken", err: errors.New("facter exited 1")},
+		static{name: "file", facts: map[string]string{Datacenter: "dc1"}},
+	}
+	if _, _, err := chain.Lookup(Datacenter); err == nil || err.Error() != "broken: facter exited 1" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// providerNames returns the name of each provider in a chain
+func providerNames(c Chain) []string {
+	var names []string
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func TestNewChain(t *testing.T) {
+	tests := map[string][]string{
+		DefaultOrder:      {"env", "file", "facter", "cloud", "system"},
+		" system , env,,": {"system", "env"},
+		"facter":          {"facter"},
+		"":                nil,
+	}
+	for order, want := range tests {
+		chain, err := NewChain(order, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := providerNames(chain); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", order, got, want)
+		}
+	}
+
+	if _, err := NewChain("env,consul,file", Options{}); err == nil || err.Error() != `unknown fact provider "consul"` {
+		t.Errorf("unexpected error %v for an unknown provider", err)
+	}
+
+	// the built in providers are configured from the options
+	opts := Options{FactsFile: "/etc/facts.yaml", FacterFacts: map[string]string{Datacenter: "dc"}, ProbeTarget: "192.0.2.1"}
+	chain, err := NewChain("file,facter,system", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := chain[0].(*File); f.Path != opts.FactsFile {
+		t.Errorf("got facts file %s", f.Path)
+	}
+	if f := chain[1].(*Facter); !reflect.DeepEqual(f.Facts, opts.FacterFacts) {
+		t.Errorf("got facter facts %v", f.Facts)
+	}
+	if s := chain[2].(*System); s.ProbeTarget != opts.ProbeTarget {
+		t.Errorf("got probe target %s", s.ProbeTarget)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	os.Setenv("TEST_FACTS_DATACENTER", "dc1")
+	os.Setenv("TEST_FACTS_DOMAIN", "")
+	defer os.Unsetenv("TEST_FACTS_DATACENTER")
+	defer os.Unsetenv("TEST_FACTS_DOMAIN")
+
+	env := &Env{Prefix: "TEST_FACTS_"}
+	tests := map[string]struct {
+		value string
+		ok    bool
+	}{
+		Datacenter: {"dc1", true},
+		// set, even if empty
+		Domain:   {"", true},
+		Hostname: {"", false},
+	}
+	for name, want := range tests {
+		value, ok, err := env.Fact(name)
+		if err != nil || value != want.value || ok != want.ok {
+			t.Errorf("%s: got %q, %t, %v", name, value, ok, err)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "facts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"facts.yaml": "datacenter: dc1\ndomain: example.com\n",
+		"facts.json": `{"datacenter": "dc1", "domain": "example.com"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f := &File{Path: path}
+		if value, ok, err := f.Fact(Domain); err != nil || !ok || value != "example.com" {
+			t.Errorf("%s: got %q, %t, %v", name, value, ok, err)
+		}
+		if _, ok, err := f.Fact(Hostname); err != nil || ok {
+			t.Errorf("%s: got %t, %v for a fact that isn't in the file", name, ok, err)
+		}
+	}
+
+	// a missing file, or none, provides no facts
+	for _, path := range []string{filepath.Join(dir, "missing.yaml"), ""} {
+		if _, ok, err := (&File{Path: path}).Fact(Datacenter); err != nil || ok {
+			t.Errorf("%q: got %t, %v", path, ok, err)
+		}
+	}
+
+	path := filepath.Join(dir, "invalid.yaml")
+	if err := ioutil.WriteFile(path, []byte("datacenter: [dc1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := (&File{Path: path}).Fact(Datacenter); err == nil {
+		t.Error("expected an error for an invalid facts file")
+	}
+}
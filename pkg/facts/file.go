@@ -0,0 +1,59 @@
+package facts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// File reads facts from a static YAML or JSON file of fact names to values.
+// A missing file provides no facts
+type File struct {
+	Path string
+
+	facts  map[string]string
+	loaded bool
+}
+
+// Name of the provider
+func (f *File) Name() string {
+	return "file"
+}
+
+func (f *File) load() error {
+	if f.loaded {
+		return nil
+	}
+	f.loaded = true
+
+	if f.Path == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		log.Debug("No facts file found at ", f.Path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, so this handles both
+	if err := yaml.Unmarshal(data, &f.facts); err != nil {
+		return fmt.Errorf("error parsing facts file %s: %v", f.Path, err)
+	}
+	return nil
+}
+
+// Fact returns the value of a fact from the facts file
+func (f *File) Fact(name string) (string, bool, error) {
+	if err := f.load(); err != nil {
+		return "", false, err
+	}
+	value, ok := f.facts[name]
+	return value, ok, nil
+}
@@ -0,0 +1,55 @@
+package facts
+
+import (
+	"fmt"
+
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+)
+
+// Interface picks the node's addresses from its network interfaces, by
+// interface name and/or subnet. It only provides the address facts, and it
+// is an error if no address or several addresses match
+type Interface struct {
+	Selector n.AddressSelector
+
+	// Addresses lists the local addresses, defaults to n.LocalAddresses
+	Addresses func() (n.Addresses, error)
+}
+
+// Name of the provider
+func (i *Interface) Name() string {
+	return "interface"
+}
+
+// Fact returns an address of the node. The IPv4 address is preferred for
+// ipaddress, the IPv6 one is used if there is no IPv4 address
+func (i *Interface) Fact(name string) (string, bool, error) {
+	if name != IPAddress && name != IPAddress6 {
+		return "", false, nil
+	}
+
+	list := i.Addresses
+	if list == nil {
+		list = n.LocalAddresses
+	}
+	addresses, err := list()
+	if err != nil {
+		return "", false, err
+	}
+
+	ip, ok, err := i.Selector.Select(addresses, name == IPAddress6)
+	if err == nil && !ok && name == IPAddress {
+		ip, ok, err = i.Selector.Select(addresses, true)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !ok {
+		family := "IP"
+		if name == IPAddress6 {
+			family = "IPv6"
+		}
+		return "", false, fmt.Errorf("no %s address found%s, the node has: %s", family, i.Selector, addresses)
+	}
+	return ip, true, nil
+}
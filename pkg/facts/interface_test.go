@@ -0,0 +1,82 @@
+package facts
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+)
+
+func testAddresses(ips ...string) func() (n.Addresses, error) {
+	return func() (n.Addresses, error) {
+		var addresses n.Addresses
+		for _, ip := range ips {
+			addresses = append(addresses, n.Address{Interface: "eth0", IP: net.ParseIP(ip)})
+		}
+		return addresses, nil
+	}
+}
+
+func TestInterface(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []string
+		fact      string
+		value     string
+		err       string
+	}{
+		{"IPv4", []string{"10.0.0.5", "fd00::5"}, IPAddress, "10.0.0.5", ""},
+		{"IPv6", []string{"10.0.0.5", "fd00::5"}, IPAddress6, "fd00::5", ""},
+		// ipaddress falls back to IPv6 on IPv6-only nodes
+		{"IPv6 only", []string{"fd00::5"}, IPAddress, "fd00::5", ""},
+		{"no IPv6", []string{"10.0.0.5"}, IPAddress6, "", "no IPv6 address found on eth0, the node has: 10.0.0.5 (eth0)"},
+		{"no addresses", nil, IPAddress, "", "no IP address found on eth0, the node has: "},
+		{"ambiguous", []string{"10.0.0.5", "10.0.0.6"}, IPAddress, "", "found several IPv4 addresses on eth0, choose one with the advertise interface or CIDR: 10.0.0.5 (eth0), 10.0.0.6 (eth0)"},
+	}
+
+	for _, test := range tests {
+		i := &Interface{Selector: n.AddressSelector{Interface: "eth0"}, Addresses: testAddresses(test.addresses...)}
+		value, ok, err := i.Fact(test.fact)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil || !ok || value != test.value {
+			t.Errorf("%s: got %q, %t, %v, want %s", test.name, value, ok, err, test.value)
+		}
+	}
+
+	// it only provides addresses
+	i := &Interface{Addresses: testAddresses("10.0.0.5")}
+	if _, ok, err := i.Fact(Hostname); ok || err != nil {
+		t.Errorf("got %t, %v for the hostname", ok, err)
+	}
+
+	i.Addresses = func() (n.Addresses, error) { return nil, errors.New("no interfaces") }
+	if _, _, err := i.Fact(IPAddress); err == nil {
+		t.Error("expected an error listing addresses")
+	}
+}
+
+func TestNewChainAddress(t *testing.T) {
+	opts := Options{Address: n.AddressSelector{CIDR: "10.0.0.0/8"}}
+	tests := map[string][]string{
+		// an address selection takes precedence
+		"env,file,system": {"interface", "env", "file", "system"},
+		// unless the order says otherwise
+		"env,interface,system": {"env", "interface", "system"},
+	}
+	for order, want := range tests {
+		chain, err := NewChain(order, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := providerNames(chain); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", order, got, want)
+		}
+	}
+}
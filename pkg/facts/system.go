@@ -0,0 +1,58 @@
+package facts
+
+import (
+	"os"
+	"strings"
+
+	n "github.com/apptio/kubeadm-bootstrap/pkg/net"
+)
+
+// System detects facts from the operating system. It's the fallback when
+// nothing else knows about the node, so it assumes no cloud provider. The IP
+// address is the one routed to ProbeTarget, or to a public address if empty
+type System struct {
+	ProbeTarget string
+}
+
+// Name of the provider
+func (s *System) Name() string {
+	return "system"
+}
+
+// Fact returns the value of a fact from the operating system
+func (s *System) Fact(name string) (string, bool, error) {
+	switch name {
+	case Hostname:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", false, err
+		}
+		return hostname, true, nil
+	case Domain:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", false, err
+		}
+		splitHostname := strings.Split(hostname, ".")
+		if len(splitHostname) < 3 {
+			return "", false, nil
+		}
+		return splitHostname[1] + "." + splitHostname[2], true, nil
+	case IPAddress:
+		ip, err := n.GetOutboundIP(s.ProbeTarget)
+		if err != nil {
+			return "", false, err
+		}
+		return ip, true, nil
+	case IPAddress6:
+		// no IPv6 route just means the node has no IPv6 address
+		ip, err := n.GetOutboundIPv6(s.ProbeTarget)
+		if err != nil {
+			return "", false, nil
+		}
+		return ip, true, nil
+	case CloudProvider:
+		return "", true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,190 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ObjectMeta is the metadata of an API object
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Secret is a kubernetes Secret. Data is base64 encoded in JSON, as the API
+// server expects
+type Secret struct {
+	APIVersion string            `json:"apiVersion,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+}
+
+// secretList is the response of listing Secrets
+type secretList struct {
+	Items []Secret `json:"items"`
+}
+
+// SecretClient manages Secrets. Client implements it against an API server,
+// FakeClient can stand in for it in tests
+type SecretClient interface {
+	// GetSecret returns a Secret by name
+	GetSecret(ctx context.Context, namespace, name string) (Secret, error)
+	// ListSecrets returns the Secrets of a type, or all Secrets if secretType
+	// is empty
+	ListSecrets(ctx context.Context, namespace, secretType string) ([]Secret, error)
+	// CreateSecret creates a Secret, which must not exist yet
+	CreateSecret(ctx context.Context, secret Secret) error
+	// DeleteSecret deletes a Secret by name
+	DeleteSecret(ctx context.Context, namespace, name string) error
+}
+
+// APIError is an error status returned by the API server
+type APIError struct {
+	Code    int
+	Reason  string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("API server returned %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// IsNotFound returns true if err is the API server not finding an object
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.Code == http.StatusNotFound
+}
+
+// IsAlreadyExists returns true if err is an object already existing
+func IsAlreadyExists(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.Code == http.StatusConflict
+}
+
+// Client is a minimal client of the kubernetes core API
+type Client struct {
+	server      string
+	bearerToken string
+	http        *http.Client
+}
+
+// NewClient creates a client for an API server
+func NewClient(cfg Config) *Client {
+	return &Client{
+		server:      cfg.Server,
+		bearerToken: cfg.BearerToken,
+		http: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: cfg.TLS, Proxy: http.ProxyFromEnvironment},
+			Timeout:   30 * time.Second,
+		},
+	}
+}
+
+// GetSecret returns a Secret by name
+func (c *Client) GetSecret(ctx context.Context, namespace, name string) (Secret, error) {
+	var secret Secret
+	err := c.do(ctx, http.MethodGet, secretsPath(namespace)+"/"+url.PathEscape(name), nil, nil, &secret)
+	return secret, err
+}
+
+// ListSecrets returns the Secrets of a type, or all Secrets if secretType is
+// empty
+func (c *Client) ListSecrets(ctx context.Context, namespace, secretType string) ([]Secret, error) {
+	query := url.Values{}
+	if secretType != "" {
+		query.Set("fieldSelector", "type="+secretType)
+	}
+
+	var list secretList
+	if err := c.do(ctx, http.MethodGet, secretsPath(namespace), query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// CreateSecret creates a Secret, which must not exist yet
+func (c *Client) CreateSecret(ctx context.Context, secret Secret) error {
+	secret.APIVersion, secret.Kind = "v1", "Secret"
+	return c.do(ctx, http.MethodPost, secretsPath(secret.Metadata.Namespace), nil, secret, nil)
+}
+
+// DeleteSecret deletes a Secret by name
+func (c *Client) DeleteSecret(ctx context.Context, namespace, name string) error {
+	return c.do(ctx, http.MethodDelete, secretsPath(namespace)+"/"+url.PathEscape(name), nil, nil, nil)
+}
+
+func secretsPath(namespace string) string {
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/secrets"
+}
+
+// do sends a request with an optional JSON body, and decodes the JSON
+// response into out if it isn't nil. Error statuses are returned as *APIError
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	u := c.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		content, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// the API server describes errors with a Status object
+		var status struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(content, &status)
+		return &APIError{Code: resp.StatusCode, Reason: status.Reason, Message: status.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("error decoding API server response to %s %s: %v", method, path, err)
+	}
+	return nil
+}
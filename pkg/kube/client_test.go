@@ -0,0 +1,254 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// secretsServer is an API server that keeps Secrets in memory, and only
+// answers requests that authenticate
+type secretsServer struct {
+	authenticated func(r *http.Request) bool
+
+	mu      sync.Mutex
+	secrets map[string]Secret
+	// requests are the method, path and query of each request
+	requests []string
+}
+
+func newSecretsServer(authenticated func(r *http.Request) bool) *secretsServer {
+	return &secretsServer{authenticated: authenticated, secrets: map[string]Secret{}}
+}
+
+func (s *secretsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, strings.TrimSuffix(r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery, "?"))
+	if !s.authenticated(r) {
+		status(w, http.StatusUnauthorized, "Unauthorized", "Unauthorized")
+		return
+	}
+
+	const prefix = "/api/v1/namespaces/kube-system/secrets"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "unexpected request", http.StatusInternalServerError)
+		return
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		var secret Secret
+		if err := json.NewDecoder(r.Body).Decode(&secret); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			status(w, http.StatusBadRequest, "BadRequest", "invalid Secret")
+			return
+		}
+		if secret.APIVersion != "v1" || secret.Kind != "Secret" {
+			status(w, http.StatusBadRequest, "BadRequest", "invalid apiVersion or kind")
+			return
+		}
+		if _, ok := s.secrets[secret.Metadata.Name]; ok {
+			status(w, http.StatusConflict, "AlreadyExists", fmt.Sprintf("secrets %q already exists", secret.Metadata.Name))
+			return
+		}
+		s.secrets[secret.Metadata.Name] = secret
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(secret)
+
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		var list secretList
+		for _, secret := range s.secrets {
+			if selector := r.URL.Query().Get("fieldSelector"); selector == "" || selector == "type="+secret.Type {
+				list.Items = append(list.Items, secret)
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+
+	case r.Method == http.MethodGet:
+		secret, ok := s.secrets[name]
+		if !ok {
+			status(w, http.StatusNotFound, "NotFound", fmt.Sprintf("secrets %q not found", name))
+			return
+		}
+		json.NewEncoder(w).Encode(secret)
+
+	case r.Method == http.MethodDelete:
+		if _, ok := s.secrets[name]; !ok {
+			status(w, http.StatusNotFound, "NotFound", fmt.Sprintf("secrets %q not found", name))
+			return
+		}
+		delete(s.secrets, name)
+		status(w, http.StatusOK, "", "")
+
+	default:
+		http.Error(w, "unexpected request", http.StatusInternalServerError)
+	}
+}
+
+// status writes a Status object, as the API server describes errors
+func status(w http.ResponseWriter, code int, reason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind": "Status", "apiVersion": "v1", "code": code, "reason": reason, "message": message,
+	})
+}
+
+// bearer authenticates requests with a bearer token
+func bearer(token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer "+token }
+}
+
+func testSecret(name, secretType string) Secret {
+	return Secret{
+		Metadata: ObjectMeta{Name: name, Namespace: "kube-system"},
+		Type:     secretType,
+		Data:     map[string][]byte{"token-id": []byte(name)},
+	}
+}
+
+func TestClientSecrets(t *testing.T) {
+	s := newSecretsServer(bearer("s3cr3t-token"))
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(Config{Server: server.URL, BearerToken: "s3cr3t-token"})
+
+	for _, secret := range []Secret{testSecret("bootstrap-token-abcdef", "bootstrap.kubernetes.io/token"), testSecret("other", "Opaque")} {
+		if err := c.CreateSecret(ctx, secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := c.GetSecret(ctx, "kube-system", "bootstrap-token-abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testSecret("bootstrap-token-abcdef", "bootstrap.kubernetes.io/token")
+	want.APIVersion, want.Kind = "v1", "Secret"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	list, err := c.ListSecrets(ctx, "kube-system", "bootstrap.kubernetes.io/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Metadata.Name != "bootstrap-token-abcdef" {
+		t.Errorf("unexpected secrets %+v", list)
+	}
+
+	if err := c.DeleteSecret(ctx, "kube-system", "bootstrap-token-abcdef"); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRequests := []string{
+		"POST /api/v1/namespaces/kube-system/secrets",
+		"POST /api/v1/namespaces/kube-system/secrets",
+		"GET /api/v1/namespaces/kube-system/secrets/bootstrap-token-abcdef",
+		"GET /api/v1/namespaces/kube-system/secrets?fieldSelector=type%3Dbootstrap.kubernetes.io%2Ftoken",
+		"DELETE /api/v1/namespaces/kube-system/secrets/bootstrap-token-abcdef",
+	}
+	if !reflect.DeepEqual(s.requests, wantRequests) {
+		t.Errorf("got requests %v, want %v", s.requests, wantRequests)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	s := newSecretsServer(bearer("s3cr3t-token"))
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(Config{Server: server.URL, BearerToken: "s3cr3t-token"})
+	if err := c.CreateSecret(ctx, testSecret("existing", "Opaque")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.GetSecret(ctx, "kube-system", "missing")
+	if !IsNotFound(err) || err.Error() != `secrets "missing" not found` {
+		t.Errorf("unexpected error %v getting a missing Secret", err)
+	}
+	if err := c.DeleteSecret(ctx, "kube-system", "missing"); !IsNotFound(err) {
+		t.Errorf("unexpected error %v deleting a missing Secret", err)
+	}
+
+	err = c.CreateSecret(ctx, testSecret("existing", "Opaque"))
+	if !IsAlreadyExists(err) || err.(*APIError).Reason != "AlreadyExists" {
+		t.Errorf("unexpected error %v creating an existing Secret", err)
+	}
+
+	// errors without a Status body are described by their code
+	_, err = c.GetSecret(ctx, "default", "unexpected")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Code != http.StatusInternalServerError || err.Error() != "API server returned 500 Internal Server Error" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// a response that isn't JSON
+	html := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "<html></html>") }))
+	defer html.Close()
+	if _, err := NewClient(Config{Server: html.URL}).ListSecrets(ctx, "kube-system", ""); err == nil || !strings.Contains(err.Error(), "error decoding API server response") {
+		t.Errorf("unexpected error %v decoding HTML", err)
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	ctx := context.Background()
+
+	// the token of a kubeconfig user, and one read from its tokenFile
+	tokens := map[string]string{"wrong token": "guess"}
+	for _, kubeContext := range []string{"inline@k2", "bootstrap@k2"} {
+		cfg, err := LoadConfig(testKubeconfig, kubeContext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[kubeContext] = cfg.BearerToken
+	}
+	for name, token := range tokens {
+		server := httptest.NewServer(newSecretsServer(bearer("s3cr3t-token")))
+		_, err := NewClient(Config{Server: server.URL, BearerToken: token}).ListSecrets(ctx, "kube-system", "")
+		if name == "wrong token" {
+			if apiErr, ok := err.(*APIError); !ok || apiErr.Code != http.StatusUnauthorized {
+				t.Errorf("%s: unexpected error %v", name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		server.Close()
+	}
+
+	// the client certificate of a kubeconfig user
+	server := httptest.NewUnstartedServer(newSecretsServer(func(r *http.Request) bool {
+		return r.TLS != nil && len(r.TLS.PeerCertificates) > 0 && r.TLS.PeerCertificates[0].Subject.CommonName == "kubernetes-admin"
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	cfg, err := LoadConfig(testKubeconfig, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Server = server.URL
+	cfg.TLS.RootCAs = x509.NewCertPool()
+	cfg.TLS.RootCAs.AddCert(server.Certificate())
+	if _, err := NewClient(cfg).ListSecrets(ctx, "kube-system", ""); err != nil {
+		t.Errorf("client certificate: %v", err)
+	}
+
+	// and without it
+	cfg.TLS.Certificates = nil
+	if _, err := NewClient(cfg).ListSecrets(ctx, "kube-system", ""); err == nil {
+		t.Error("expected an error without a client certificate")
+	}
+}
@@ -0,0 +1,107 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// FakeClient keeps Secrets in memory, for tests. It returns the same errors
+// as the API server for missing and existing Secrets
+type FakeClient struct {
+	mu      sync.Mutex
+	secrets map[string]Secret
+}
+
+// NewFakeClient creates a fake client holding some Secrets
+func NewFakeClient(secrets ...Secret) *FakeClient {
+	f := &FakeClient{secrets: map[string]Secret{}}
+	for _, secret := range secrets {
+		f.secrets[key(secret.Metadata.Namespace, secret.Metadata.Name)] = secret
+	}
+	return f
+}
+
+// GetSecret returns a Secret by name
+func (f *FakeClient) GetSecret(ctx context.Context, namespace, name string) (Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return Secret{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secret, ok := f.secrets[key(namespace, name)]
+	if !ok {
+		return Secret{}, notFound(name)
+	}
+	return secret, nil
+}
+
+// ListSecrets returns the Secrets of a type sorted by name, or all Secrets
+// if secretType is empty
+func (f *FakeClient) ListSecrets(ctx context.Context, namespace, secretType string) ([]Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var secrets []Secret
+	for _, secret := range f.secrets {
+		if secret.Metadata.Namespace == namespace && (secretType == "" || secret.Type == secretType) {
+			secrets = append(secrets, secret)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Metadata.Name < secrets[j].Metadata.Name })
+	return secrets, nil
+}
+
+// CreateSecret creates a Secret, which must not exist yet
+func (f *FakeClient) CreateSecret(ctx context.Context, secret Secret) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	k := key(secret.Metadata.Namespace, secret.Metadata.Name)
+	if _, ok := f.secrets[k]; ok {
+		return &APIError{
+			Code:    http.StatusConflict,
+			Reason:  "AlreadyExists",
+			Message: fmt.Sprintf("secrets %q already exists", secret.Metadata.Name),
+		}
+	}
+	f.secrets[k] = secret
+	return nil
+}
+
+// DeleteSecret deletes a Secret by name
+func (f *FakeClient) DeleteSecret(ctx context.Context, namespace, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	k := key(namespace, name)
+	if _, ok := f.secrets[k]; !ok {
+		return notFound(name)
+	}
+	delete(f.secrets, k)
+	return nil
+}
+
+func key(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func notFound(name string) error {
+	return &APIError{
+		Code:    http.StatusNotFound,
+		Reason:  "NotFound",
+		Message: fmt.Sprintf("secrets %q not found", name),
+	}
+}
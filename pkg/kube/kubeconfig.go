@@ -0,0 +1,187 @@
+package kube
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultKubeconfig is the admin kubeconfig kubeadm writes on masters
+const DefaultKubeconfig = "/etc/kubernetes/admin.conf"
+
+// kubeconfig is the part of a kubeconfig file needed to reach the API server
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// Config is how to reach and authenticate to an API server
+type Config struct {
+	Server      string
+	TLS         *tls.Config
+	BearerToken string
+}
+
+// KubeconfigPath returns the kubeconfig to use: path if set, then
+// $KUBECONFIG, then the kubeadm admin kubeconfig
+func KubeconfigPath(path string) string {
+	if path != "" {
+		return path
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		// only the first of several kubeconfigs is used, they aren't merged
+		return filepath.SplitList(env)[0]
+	}
+	return DefaultKubeconfig
+}
+
+// LoadConfig reads the cluster and user of a context from a kubeconfig file,
+// or of its current context if context is empty. Client certificate and
+// bearer token users are supported
+func LoadConfig(path, context string) (Config, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal(content, &kc); err != nil {
+		return Config{}, fmt.Errorf("error parsing kubeconfig %s: %v", path, err)
+	}
+	// relative paths are relative to the kubeconfig
+	dir := filepath.Dir(path)
+
+	if context == "" {
+		context = kc.CurrentContext
+	}
+	if context == "" {
+		return Config{}, fmt.Errorf("kubeconfig %s has no current context", path)
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == context {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return Config{}, fmt.Errorf("context %s not found in kubeconfig %s", context, path)
+	}
+
+	cfg := Config{TLS: &tls.Config{}}
+	found = false
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		cfg.Server = strings.TrimSuffix(c.Cluster.Server, "/")
+		cfg.TLS.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+
+		ca, err := readData(dir, c.Cluster.CertificateAuthority, c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return Config{}, fmt.Errorf("error reading the CA of cluster %s: %v", clusterName, err)
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return Config{}, fmt.Errorf("no PEM encoded certificates in the CA of cluster %s", clusterName)
+			}
+			cfg.TLS.RootCAs = pool
+		}
+		break
+	}
+	if !found {
+		return Config{}, fmt.Errorf("cluster %s not found in kubeconfig %s", clusterName, path)
+	}
+	if cfg.Server == "" {
+		return Config{}, fmt.Errorf("cluster %s has no server", clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+
+		cert, err := readData(dir, u.User.ClientCertificate, u.User.ClientCertificateData)
+		if err != nil {
+			return Config{}, fmt.Errorf("error reading the client certificate of user %s: %v", userName, err)
+		}
+		key, err := readData(dir, u.User.ClientKey, u.User.ClientKeyData)
+		if err != nil {
+			return Config{}, fmt.Errorf("error reading the client key of user %s: %v", userName, err)
+		}
+		if cert != nil || key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid client certificate of user %s: %v", userName, err)
+			}
+			cfg.TLS.Certificates = []tls.Certificate{pair}
+		}
+
+		cfg.BearerToken = u.User.Token
+		if cfg.BearerToken == "" && u.User.TokenFile != "" {
+			token, err := ioutil.ReadFile(resolvePath(dir, u.User.TokenFile))
+			if err != nil {
+				return Config{}, fmt.Errorf("error reading the token of user %s: %v", userName, err)
+			}
+			cfg.BearerToken = strings.TrimSpace(string(token))
+		}
+		break
+	}
+
+	return cfg, nil
+}
+
+// readData returns base64 encoded inline data, or the contents of a file
+func readData(dir, file, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return ioutil.ReadFile(resolvePath(dir, file))
+	}
+	return nil, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,81 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = "testdata/kubeconfig"
+
+func TestLoadConfigCurrentContext(t *testing.T) {
+	cfg, err := LoadConfig(testKubeconfig, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server != "https://dc1-k1.service.discover:6443" {
+		t.Errorf("unexpected server %s", cfg.Server)
+	}
+	if cfg.TLS.RootCAs == nil {
+		t.Error("expected the CA file to be loaded")
+	}
+	if len(cfg.TLS.Certificates) != 1 {
+		t.Errorf("expected a client certificate, got %d", len(cfg.TLS.Certificates))
+	}
+	if cfg.BearerToken != "" {
+		t.Errorf("unexpected bearer token %q", cfg.BearerToken)
+	}
+}
+
+func TestLoadConfigContext(t *testing.T) {
+	cfg, err := LoadConfig(testKubeconfig, "bootstrap@k2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server != "https://dc1-k2.service.discover:6443" {
+		t.Errorf("unexpected server %s", cfg.Server)
+	}
+	if cfg.TLS.RootCAs == nil {
+		t.Error("expected the inline CA to be loaded")
+	}
+	if len(cfg.TLS.Certificates) != 0 {
+		t.Errorf("unexpected client certificates %d", len(cfg.TLS.Certificates))
+	}
+	// read from a file relative to the kubeconfig
+	if cfg.BearerToken != "s3cr3t-token" {
+		t.Errorf("unexpected bearer token %q", cfg.BearerToken)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := map[string]struct{ path, context string }{
+		"missing file":    {"testdata/missing", ""},
+		"missing context": {testKubeconfig, "nope"},
+		"missing cluster": {testKubeconfig, "missing-cluster"},
+	}
+
+	for name, test := range tests {
+		if _, err := LoadConfig(test.path, test.context); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+
+	os.Setenv("KUBECONFIG", "")
+	if path := KubeconfigPath(""); path != DefaultKubeconfig {
+		t.Errorf("got %s, want %s", path, DefaultKubeconfig)
+	}
+
+	os.Setenv("KUBECONFIG", filepath.Join("a", "config")+string(filepath.ListSeparator)+filepath.Join("b", "config"))
+	if path := KubeconfigPath(""); path != filepath.Join("a", "config") {
+		t.Errorf("got %s, want the first kubeconfig", path)
+	}
+	if path := KubeconfigPath("admin.conf"); path != "admin.conf" {
+		t.Errorf("got %s, want the given path", path)
+	}
+}
@@ -0,0 +1,200 @@
+package naming
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Vars are what naming templates can use, e.g. {{.Datacenter}}
+type Vars struct {
+	Datacenter string
+	Cluster    string
+	Domain     string
+	// DiscoveryDomain is the service discovery domain a name is in, e.g.
+	// service.discover for Consul. Templates using it are rendered for each
+	// discovery domain
+	DiscoveryDomain string
+	// Index is the number of a master or etcd member, starting at 1, and 0
+	// for the names of the whole cluster
+	Index int
+}
+
+// Conventions are the Go text/template strings that masters, etcd members
+// and the control plane are named with. Empty fields use the defaults
+type Conventions struct {
+	// Master names each master, e.g. {{.Datacenter}}-{{.Cluster}}master-{{.Index}}.{{.Domain}}
+	Master string `json:"master"`
+	// Etcd names each external etcd member
+	Etcd string `json:"etcd"`
+	// Endpoint is the name nodes reach the control plane on. It uses the
+	// first discovery domain
+	Endpoint string `json:"endpoint"`
+	// Discovery are the other names of the control plane, added to the API
+	// server's cert SANs
+	Discovery []string `json:"discovery"`
+	// DiscoveryDomains are the service discovery domains the control plane
+	// is registered in. nil uses the default, an empty list means there are
+	// none and discovery names using them are left out
+	DiscoveryDomains []string `json:"discoveryDomains"`
+}
+
+// Default are the conventions of Apptio's datacenters, where the second
+// master of cluster k1 in dc1 is dc1-k1master-2.example.com and the control
+// plane is the consul service dc1-k1.service.discover
+var Default = Conventions{
+	Master:   "{{.Datacenter}}-{{.Cluster}}master-{{.Index}}.{{.Domain}}",
+	Etcd:     "{{.Datacenter}}-{{.Cluster}}etcd-{{.Index}}.{{.Domain}}",
+	Endpoint: "{{.Datacenter}}-{{.Cluster}}.{{.DiscoveryDomain}}",
+	Discovery: []string{
+		"{{.Datacenter}}-{{.Cluster}}master.{{.Domain}}",
+		"{{.Cluster}}.{{.DiscoveryDomain}}",
+		"{{.Datacenter}}-{{.Cluster}}.{{.DiscoveryDomain}}",
+		"{{.Datacenter}}-{{.Cluster}}.{{.Datacenter}}.{{.DiscoveryDomain}}",
+	},
+	DiscoveryDomains: []string{"service.discover"},
+}
+
+// WithDefaults fills in the default of each convention that isn't set
+func (c Conventions) WithDefaults() Conventions {
+	if c.Master == "" {
+		c.Master = Default.Master
+	}
+	if c.Etcd == "" {
+		c.Etcd = Default.Etcd
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = Default.Endpoint
+	}
+	if len(c.Discovery) == 0 {
+		c.Discovery = Default.Discovery
+	}
+	if c.DiscoveryDomains == nil {
+		c.DiscoveryDomains = Default.DiscoveryDomains
+	}
+	return c
+}
+
+// Validate checks every template renders, and that masters and etcd members
+// get a name each
+func (c Conventions) Validate() error {
+	sample := Vars{Datacenter: "dc1", Cluster: "k1", Domain: "example.com", DiscoveryDomain: "service.discover"}
+
+	for _, numbered := range []struct{ name, tmpl string }{{"master", c.Master}, {"etcd", c.Etcd}} {
+		first, err := Name(numbered.tmpl, withIndex(sample, 1))
+		if err != nil {
+			return fmt.Errorf("invalid %s naming template: %v", numbered.name, err)
+		}
+		second, err := Name(numbered.tmpl, withIndex(sample, 2))
+		if err != nil {
+			return fmt.Errorf("invalid %s naming template: %v", numbered.name, err)
+		}
+		if first == second {
+			return fmt.Errorf("invalid %s naming template %q, must use {{.Index}} to name each one", numbered.name, numbered.tmpl)
+		}
+	}
+
+	if _, err := Name(c.Endpoint, sample); err != nil {
+		return fmt.Errorf("invalid endpoint naming template: %v", err)
+	}
+	if usesDiscoveryDomain(c.Endpoint) && len(c.DiscoveryDomains) == 0 {
+		return fmt.Errorf("endpoint naming template %q uses {{.DiscoveryDomain}}, but there are no discovery domains", c.Endpoint)
+	}
+	for _, tmpl := range c.Discovery {
+		if _, err := Name(tmpl, sample); err != nil {
+			return fmt.Errorf("invalid discovery naming template: %v", err)
+		}
+	}
+	for _, domain := range c.DiscoveryDomains {
+		if domain == "" || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+			return fmt.Errorf("invalid discovery domain %q", domain)
+		}
+	}
+	return nil
+}
+
+// MasterNames returns the names of the masters of a cluster
+func (c Conventions) MasterNames(v Vars, count int) ([]string, error) {
+	return numbered(c.Master, v, count)
+}
+
+// EtcdNames returns the names of the external etcd members of a cluster
+func (c Conventions) EtcdNames(v Vars, count int) ([]string, error) {
+	return numbered(c.Etcd, v, count)
+}
+
+// EndpointName returns the name nodes reach the control plane on
+func (c Conventions) EndpointName(v Vars) (string, error) {
+	if len(c.DiscoveryDomains) > 0 {
+		v.DiscoveryDomain = c.DiscoveryDomains[0]
+	}
+	return Name(c.Endpoint, v)
+}
+
+// DiscoveryNames returns the other names of the control plane. Templates
+// using the discovery domain are rendered for each one, and left out if
+// there are none
+func (c Conventions) DiscoveryNames(v Vars) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, tmpl := range c.Discovery {
+		domains := []string{""}
+		if usesDiscoveryDomain(tmpl) {
+			domains = c.DiscoveryDomains
+		}
+		for _, domain := range domains {
+			v.DiscoveryDomain = domain
+			name, err := Name(tmpl, v)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// Name renders a naming template
+func Name(tmpl string, v Vars) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, v); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("template %q renders an empty name", tmpl)
+	}
+	return name, nil
+}
+
+// numbered renders a template for each index from 1 to count
+func numbered(tmpl string, v Vars, count int) ([]string, error) {
+	var names []string
+	for i := 1; i <= count; i++ {
+		name, err := Name(tmpl, withIndex(v, i))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// usesDiscoveryDomain returns true if a template uses the discovery domain
+func usesDiscoveryDomain(tmpl string) bool {
+	return strings.Contains(tmpl, ".DiscoveryDomain")
+}
+
+func withIndex(v Vars, index int) Vars {
+	v.Index = index
+	return v
+}
@@ -0,0 +1,138 @@
+package naming
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testVars = Vars{Datacenter: "dc1", Cluster: "k1", Domain: "example.com"}
+
+func TestDefault(t *testing.T) {
+	if err := Default.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	conventions := Conventions{}.WithDefaults()
+	if !reflect.DeepEqual(conventions, Default) {
+		t.Errorf("got %+v, want the defaults", conventions)
+	}
+
+	// the names masters and etcd members always had
+	for _, v := range []Vars{testVars, {Datacenter: "us-west-2", Cluster: "prod", Domain: "k8s.example.org"}} {
+		masters, err := conventions.MasterNames(v, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		etcd, err := conventions.EtcdNames(v, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i <= 3; i++ {
+			if want := fmt.Sprintf("%s-%smaster-%d.%s", v.Datacenter, v.Cluster, i, v.Domain); masters[i-1] != want {
+				t.Errorf("got master %s, want %s", masters[i-1], want)
+			}
+			if want := fmt.Sprintf("%s-%setcd-%d.%s", v.Datacenter, v.Cluster, i, v.Domain); etcd[i-1] != want {
+				t.Errorf("got etcd member %s, want %s", etcd[i-1], want)
+			}
+		}
+	}
+
+	endpoint, err := conventions.EndpointName(testVars)
+	if err != nil || endpoint != "dc1-k1.service.discover" {
+		t.Errorf("got endpoint %s, %v", endpoint, err)
+	}
+
+	names, err := conventions.DiscoveryNames(testVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"dc1-k1master.example.com", "k1.service.discover", "dc1-k1.service.discover", "dc1-k1.dc1.service.discover"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got discovery names %v, want %v", names, want)
+	}
+}
+
+func TestCustom(t *testing.T) {
+	conventions := Conventions{
+		Master:           "{{.Cluster}}-m{{.Index}}.{{.Datacenter}}.{{.Domain}}",
+		Endpoint:         "api.{{.Cluster}}.{{.DiscoveryDomain}}",
+		Discovery:        []string{"{{.Cluster}}.{{.DiscoveryDomain}}", "{{.Cluster}}-lb.{{.Domain}}", " {{.Cluster}}-lb.{{.Domain}} "},
+		DiscoveryDomains: []string{"consul", "service.discover"},
+	}.WithDefaults()
+	if err := conventions.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	masters, err := conventions.MasterNames(testVars, 2)
+	if err != nil || !reflect.DeepEqual(masters, []string{"k1-m1.dc1.example.com", "k1-m2.dc1.example.com"}) {
+		t.Errorf("got masters %v, %v", masters, err)
+	}
+	// conventions that aren't set keep the default
+	etcd, err := conventions.EtcdNames(testVars, 1)
+	if err != nil || !reflect.DeepEqual(etcd, []string{"dc1-k1etcd-1.example.com"}) {
+		t.Errorf("got etcd members %v, %v", etcd, err)
+	}
+
+	// the endpoint is in the first discovery domain
+	endpoint, err := conventions.EndpointName(testVars)
+	if err != nil || endpoint != "api.k1.consul" {
+		t.Errorf("got endpoint %s, %v", endpoint, err)
+	}
+
+	// names are rendered for each discovery domain, trimmed, and only
+	// listed once
+	names, err := conventions.DiscoveryNames(testVars)
+	if err != nil || !reflect.DeepEqual(names, []string{"k1.consul", "k1.service.discover", "k1-lb.example.com"}) {
+		t.Errorf("got discovery names %v, %v", names, err)
+	}
+
+	// without discovery domains, the names using them are left out
+	conventions.DiscoveryDomains = []string{}
+	conventions.Endpoint = "{{.Cluster}}-lb.{{.Domain}}"
+	if err := conventions.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	names, err = conventions.DiscoveryNames(testVars)
+	if err != nil || !reflect.DeepEqual(names, []string{"k1-lb.example.com"}) {
+		t.Errorf("got discovery names %v, %v without discovery domains", names, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		conventions Conventions
+		want        string
+	}{
+		{Conventions{Master: "{{.Datacenter}}-master-{{.Index}"}, "invalid master naming template: "},
+		{Conventions{Etcd: "{{.Region}}-etcd-{{.Index}}"}, "invalid etcd naming template: "},
+		{Conventions{Master: "{{.Datacenter}}-{{.Cluster}}master.{{.Domain}}"}, `invalid master naming template "{{.Datacenter}}-{{.Cluster}}master.{{.Domain}}", must use {{.Index}} to name each one`},
+		{Conventions{Endpoint: "{{if false}}{{.Cluster}}{{end}}"}, `invalid endpoint naming template: template "{{if false}}{{.Cluster}}{{end}}" renders an empty name`},
+		{Conventions{Endpoint: "{{.Cluster}}.{{.DiscoveryDomain}}", DiscoveryDomains: []string{}}, "endpoint naming template \"{{.Cluster}}.{{.DiscoveryDomain}}\" uses {{.DiscoveryDomain}}, but there are no discovery domains"},
+		{Conventions{Discovery: []string{"{{.Cluster}}", "{{.Cluster"}}, "invalid discovery naming template: "},
+		{Conventions{DiscoveryDomains: []string{"consul", ".service.discover"}}, `invalid discovery domain ".service.discover"`},
+		{Conventions{DiscoveryDomains: []string{""}}, `invalid discovery domain ""`},
+	}
+
+	for _, test := range tests {
+		err := test.conventions.WithDefaults().Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%+v: got error %v, want %s", test.conventions, err, test.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	name, err := Name("  {{.Datacenter}}-{{.Cluster}}-{{.Index}}\n", withIndex(testVars, 7))
+	if err != nil || name != "dc1-k1-7" {
+		t.Errorf("got %q, %v", name, err)
+	}
+
+	if _, err := Name("{{.Cluster | upper}}", testVars); err == nil {
+		t.Error("expected an error for an unknown function")
+	}
+	if _, err := Name("{{.Region}}", testVars); err == nil || !strings.Contains(err.Error(), "Region") {
+		t.Errorf("unexpected error %v for an unknown var", err)
+	}
+}
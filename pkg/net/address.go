@@ -0,0 +1,144 @@
+package net
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Address is a local address and the interface it's on
+type Address struct {
+	Interface string
+	IP        net.IP
+}
+
+func (a Address) String() string {
+	return a.IP.String() + " (" + a.Interface + ")"
+}
+
+// Addresses are candidate node addresses
+type Addresses []Address
+
+func (a Addresses) String() string {
+	var s []string
+	for _, address := range a {
+		s = append(s, address.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+// LocalAddresses returns the global unicast addresses of the interfaces that
+// are up, which are the addresses a node can be reached on
+func LocalAddresses() (Addresses, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses Addresses
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the addresses of %s: %v", iface.Name, err)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.IsGlobalUnicast() {
+				continue
+			}
+			addresses = append(addresses, Address{Interface: iface.Name, IP: ipNet.IP})
+		}
+	}
+	return addresses, nil
+}
+
+// family returns the addresses of one IP family
+func (a Addresses) family(ipv6 bool) Addresses {
+	var matching Addresses
+	for _, address := range a {
+		if (address.IP.To4() == nil) == ipv6 {
+			matching = append(matching, address)
+		}
+	}
+	return matching
+}
+
+// AddressSelector picks the node's address from its interfaces. Interface
+// picks the address of a network interface, CIDR the address in a subnet,
+// and both can be combined. CIDR can be an IPv4 and an IPv6 subnet separated
+// by a comma, for dual-stack nodes
+type AddressSelector struct {
+	Interface string
+	CIDR      string
+}
+
+// Set returns true if the selector has anything to select by
+func (s AddressSelector) Set() bool {
+	return s.Interface != "" || s.CIDR != ""
+}
+
+// Select returns the matching address of an IP family. ok is false if there
+// is none, and it is an error if there are several to choose from
+func (s AddressSelector) Select(addresses Addresses, ipv6 bool) (ip string, ok bool, err error) {
+	family := "IPv4"
+	if ipv6 {
+		family = "IPv6"
+	}
+
+	matching := addresses.family(ipv6)
+	if s.Interface != "" {
+		var onInterface Addresses
+		for _, address := range matching {
+			if address.Interface == s.Interface {
+				onInterface = append(onInterface, address)
+			}
+		}
+		matching = onInterface
+	}
+	if s.CIDR != "" {
+		var subnets []*net.IPNet
+		for _, cidr := range strings.Split(s.CIDR, ",") {
+			_, subnet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+			if err != nil {
+				return "", false, fmt.Errorf("invalid advertise CIDR %s", cidr)
+			}
+			subnets = append(subnets, subnet)
+		}
+		var inSubnet Addresses
+		for _, address := range matching {
+			for _, subnet := range subnets {
+				if subnet.Contains(address.IP) {
+					inSubnet = append(inSubnet, address)
+					break
+				}
+			}
+		}
+		matching = inSubnet
+	}
+
+	switch len(matching) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return matching[0].IP.String(), true, nil
+	}
+	return "", false, fmt.Errorf("found several %s addresses%s, choose one with the advertise interface or CIDR: %s", family, s, matching)
+}
+
+// String describes what the selector selects by, with a leading space
+func (s AddressSelector) String() string {
+	var by []string
+	if s.Interface != "" {
+		by = append(by, "on "+s.Interface)
+	}
+	if s.CIDR != "" {
+		by = append(by, "in "+s.CIDR)
+	}
+	if len(by) == 0 {
+		return ""
+	}
+	return " " + strings.Join(by, " and ")
+}
@@ -0,0 +1,86 @@
+package net
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+// testAddresses are a dual-stack node's addresses on two networks
+var testAddresses = Addresses{
+	{Interface: "eth0", IP: net.ParseIP("10.0.0.5")},
+	{Interface: "eth0", IP: net.ParseIP("fd00:10::5")},
+	{Interface: "eth1", IP: net.ParseIP("192.168.1.5")},
+	{Interface: "eth1", IP: net.ParseIP("fd00:192::5")},
+	{Interface: "docker0", IP: net.ParseIP("172.17.0.1")},
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector AddressSelector
+		ipv6     bool
+		want     string
+		// err is the start of the error
+		err string
+	}{
+		{"interface", AddressSelector{Interface: "eth1"}, false, "192.168.1.5", ""},
+		{"interface IPv6", AddressSelector{Interface: "eth1"}, true, "fd00:192::5", ""},
+		{"interface without IPv6", AddressSelector{Interface: "docker0"}, true, "", ""},
+		{"unknown interface", AddressSelector{Interface: "bond0"}, false, "", ""},
+		{"CIDR", AddressSelector{CIDR: "10.0.0.0/8"}, false, "10.0.0.5", ""},
+		{"CIDR of the other family", AddressSelector{CIDR: "10.0.0.0/8"}, true, "", ""},
+		{"CIDR with no addresses", AddressSelector{CIDR: "10.1.0.0/16"}, false, "", ""},
+		{"dual-stack CIDR", AddressSelector{CIDR: "192.168.0.0/16, fd00:192::/64"}, false, "192.168.1.5", ""},
+		{"dual-stack CIDR IPv6", AddressSelector{CIDR: "192.168.0.0/16, fd00:192::/64"}, true, "fd00:192::5", ""},
+		{"interface and CIDR", AddressSelector{Interface: "eth0", CIDR: "10.0.0.0/8,172.16.0.0/12"}, false, "10.0.0.5", ""},
+		{"interface and CIDR that don't match", AddressSelector{Interface: "eth1", CIDR: "10.0.0.0/8"}, false, "", ""},
+
+		{
+			"ambiguous",
+			AddressSelector{},
+			false,
+			"",
+			"found several IPv4 addresses, choose one with the advertise interface or CIDR: 10.0.0.5 (eth0), 192.168.1.5 (eth1), 172.17.0.1 (docker0)",
+		},
+		{
+			"ambiguous CIDR",
+			AddressSelector{CIDR: "fd00::/8"},
+			true,
+			"",
+			"found several IPv6 addresses in fd00::/8, choose one with the advertise interface or CIDR: fd00:10::5 (eth0), fd00:192::5 (eth1)",
+		},
+		{"invalid CIDR", AddressSelector{CIDR: "10.0.0.0/8,fd00::/129"}, false, "", "invalid advertise CIDR fd00::/129"},
+		{"CIDR without a prefix length", AddressSelector{Interface: "eth0", CIDR: "10.0.0.5"}, false, "", "invalid advertise CIDR 10.0.0.5"},
+	}
+
+	for _, test := range tests {
+		ip, ok, err := test.selector.Select(testAddresses, test.ipv6)
+		if test.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil || ip != test.want || ok != (test.want != "") {
+			t.Errorf("%s: got %q, %t, %v, want %q", test.name, ip, ok, err, test.want)
+		}
+	}
+}
+
+func TestSelectorString(t *testing.T) {
+	tests := map[AddressSelector]string{
+		{}:                                    "",
+		{Interface: "eth0"}:                   " on eth0",
+		{CIDR: "10.0.0.0/8"}:                  " in 10.0.0.0/8",
+		{Interface: "eth0", CIDR: "fd00::/8"}: " on eth0 and in fd00::/8",
+	}
+	for selector, want := range tests {
+		if got := selector.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if selector.Set() != (want != "") {
+			t.Errorf("%+v: Set() is %t", selector, selector.Set())
+		}
+	}
+}
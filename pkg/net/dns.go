@@ -1,41 +1,133 @@
 package net
 
 import (
+	"context"
 	"fmt"
-	log "github.com/Sirupsen/logrus"
-	"github.com/bogdanovich/dns_resolver"
+	"net"
+	"sort"
 	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
 )
 
-//GetMasterAddresses Get preferred outbound ip of this machine
-func GetMasterAddresses(dcName string, clusterName string, domainName string, size int, svcIP string) string {
+// Resolver looks up DNS records. *net.Resolver implements it, FakeResolver
+// can stand in for it in tests
+type Resolver interface {
+	// LookupHost returns the A and AAAA records of a host
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	// LookupSRV returns the SRV records of a service, or of name directly if
+	// service and proto are empty
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// DefaultResolver uses the system's resolver configuration
+var DefaultResolver Resolver = net.DefaultResolver
 
-	resolver, err := dns_resolver.NewFromResolvConf("/etc/resolv.conf")
+// LookupError is a name that couldn't be resolved
+type LookupError struct {
+	Name string
+	Err  error
+}
 
-	if err != nil {
-		log.Fatal(err)
-	}
-	// In case of i/o timeout
-	resolver.RetryTimes = 5
-
-	// loop through all the masters
-	// lookup the IPs
-	// append to a slice which is returned as a CSV
-	var ips []string
-	i := 1
-	for i <= size {
-		hostname := fmt.Sprintf("%s-%smaster-%d.%s", dcName, clusterName, i, domainName)
-		log.Debug("Looking up host: ", hostname)
-		addresses, err := resolver.LookupHost(hostname)
-		if err != nil {
-			log.Fatal("Error resolving hostname: ", err)
+func (e LookupError) Error() string {
+	// DNS errors already name the host
+	if dnsErr, ok := e.Err.(*net.DNSError); ok {
+		return fmt.Sprintf("%s: %s", e.Name, dnsErr.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+// LookupErrors are all the names that couldn't be resolved
+type LookupErrors []LookupError
+
+func (e LookupErrors) Error() string {
+	var failed []string
+	for _, err := range e {
+		failed = append(failed, err.Error())
+	}
+	return "error resolving " + strings.Join(failed, ", ")
+}
+
+// Names returns the names that couldn't be resolved
+func (e LookupErrors) Names() []string {
+	var names []string
+	for _, err := range e {
+		names = append(names, err.Name)
+	}
+	return names
+}
+
+// LookupHosts resolves names concurrently and returns all their addresses,
+// in the order of the names. If any name fails, the error is a LookupErrors
+// listing every name that did
+func LookupHosts(ctx context.Context, r Resolver, names []string) ([]string, error) {
+	results := make([][]string, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			log.Debug("Looking up host: ", name)
+			addresses, err := r.LookupHost(ctx, name)
+			if err == nil && len(addresses) == 0 {
+				err = fmt.Errorf("no addresses found")
+			}
+			results[i], errs[i] = addresses, err
+		}(i, name)
+	}
+	wg.Wait()
+
+	var failed LookupErrors
+	var addresses []string
+	seen := map[string]bool{}
+	for i, name := range names {
+		if errs[i] != nil {
+			failed = append(failed, LookupError{Name: name, Err: errs[i]})
+			continue
+		}
+		for _, address := range results[i] {
+			if !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
 		}
-		ips = append(ips, addresses[0].String())
-		i++
 	}
+	if len(failed) > 0 {
+		return nil, failed
+	}
+	return addresses, nil
+}
 
-	ips = append(ips, svcIP)
+// LookupSRV finds the hosts behind an SRV name, such as a Consul service
+// name like dc1-k1master.service.discover, and resolves them. The hosts are
+// returned sorted, along with their addresses
+func LookupSRV(ctx context.Context, r Resolver, name string) ([]string, []string, error) {
+	log.Debug("Looking up SRV records: ", name)
+	_, records, err := r.LookupSRV(ctx, "", "", name)
+	if err == nil && len(records) == 0 {
+		err = fmt.Errorf("no SRV records found")
+	}
+	if err != nil {
+		return nil, nil, LookupErrors{{Name: name, Err: err}}
+	}
 
-	return strings.Join(ips, ",")
+	var hosts []string
+	seen := map[string]bool{}
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
 
+	addresses, err := LookupHosts(ctx, r, hosts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hosts, addresses, nil
 }
@@ -0,0 +1,155 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// slowResolver delays the lookups of some hosts, and blocks until the context
+// is done for hosts that hang
+type slowResolver struct {
+	FakeResolver
+	delays map[string]time.Duration
+	hang   map[string]bool
+}
+
+func (s slowResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if s.hang[host] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(s.delays[host])
+	return s.FakeResolver.LookupHost(ctx, host)
+}
+
+var testHosts = map[string][]string{
+	"master-1.example.com": {"10.0.0.1"},
+	"master-2.example.com": {"10.0.0.2", "fd00::2"},
+	"master-3.example.com": {"10.0.0.3"},
+	"alias.example.com":    {"10.0.0.1"},
+}
+
+func TestLookupHostsOrder(t *testing.T) {
+	// the first names resolve last, but their addresses still come first
+	r := slowResolver{
+		FakeResolver: FakeResolver{Hosts: testHosts},
+		delays: map[string]time.Duration{
+			"master-1.example.com": 30 * time.Millisecond,
+			"master-2.example.com": 20 * time.Millisecond,
+			"master-3.example.com": 10 * time.Millisecond,
+		},
+	}
+
+	names := []string{"master-1.example.com", "master-2.example.com", "master-3.example.com", "alias.example.com"}
+	addresses, err := LookupHosts(context.Background(), r, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.1", "10.0.0.2", "fd00::2", "10.0.0.3"}
+	if !reflect.DeepEqual(addresses, want) {
+		t.Errorf("got %v, want %v", addresses, want)
+	}
+}
+
+func TestLookupHostsPartialFailure(t *testing.T) {
+	r := FakeResolver{
+		Hosts: map[string][]string{
+			"master-1.example.com": {"10.0.0.1"},
+			"master-3.example.com": {},
+		},
+		Errors: map[string]error{
+			"master-4.example.com": &net.DNSError{Err: "server misbehaving", Name: "master-4.example.com"},
+		},
+	}
+
+	names := []string{"master-1.example.com", "master-2.example.com", "master-3.example.com", "master-4.example.com"}
+	addresses, err := LookupHosts(context.Background(), r, names)
+	if addresses != nil {
+		t.Errorf("unexpected addresses %v", addresses)
+	}
+	failed, ok := err.(LookupErrors)
+	if !ok {
+		t.Fatalf("got error %v, want LookupErrors", err)
+	}
+
+	want := []string{"master-2.example.com", "master-3.example.com", "master-4.example.com"}
+	if !reflect.DeepEqual(failed.Names(), want) {
+		t.Errorf("got failed names %v, want %v", failed.Names(), want)
+	}
+	wantErr := "error resolving master-2.example.com: no such host, master-3.example.com: no addresses found, master-4.example.com: server misbehaving"
+	if err.Error() != wantErr {
+		t.Errorf("got error %q, want %q", err, wantErr)
+	}
+}
+
+func TestLookupHostsTimeout(t *testing.T) {
+	r := slowResolver{
+		FakeResolver: FakeResolver{Hosts: testHosts},
+		hang:         map[string]bool{"master-2.example.com": true},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := LookupHosts(ctx, r, []string{"master-1.example.com", "master-2.example.com"})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("lookup took %s, longer than the timeout", elapsed)
+	}
+
+	failed, ok := err.(LookupErrors)
+	if !ok || len(failed) != 1 {
+		t.Fatalf("got error %v, want one LookupError", err)
+	}
+	if failed[0].Name != "master-2.example.com" || failed[0].Err != context.DeadlineExceeded {
+		t.Errorf("unexpected error %v", failed[0])
+	}
+}
+
+func TestLookupSRV(t *testing.T) {
+	r := FakeResolver{
+		Hosts: testHosts,
+		SRV: map[string][]string{
+			// a host registered twice is only returned once
+			"k1master.service.discover": {"master-3.example.com", "master-1.example.com", "master-3.example.com"},
+		},
+	}
+
+	hosts, addresses, err := LookupSRV(context.Background(), r, "k1master.service.discover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"master-1.example.com", "master-3.example.com"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("got hosts %v, want %v", hosts, want)
+	}
+	if want := []string{"10.0.0.1", "10.0.0.3"}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("got addresses %v, want %v", addresses, want)
+	}
+}
+
+func TestLookupSRVErrors(t *testing.T) {
+	r := FakeResolver{
+		Hosts: testHosts,
+		SRV: map[string][]string{
+			"empty.service.discover":   {},
+			"missing.service.discover": {"master-9.example.com"},
+		},
+		Errors: map[string]error{"broken.service.discover": errors.New("refused")},
+	}
+
+	tests := map[string]string{
+		"empty.service.discover":   "error resolving empty.service.discover: no SRV records found",
+		"broken.service.discover":  "error resolving broken.service.discover: refused",
+		"missing.service.discover": "error resolving master-9.example.com: no such host",
+	}
+	for name, want := range tests {
+		_, _, err := LookupSRV(context.Background(), r, name)
+		if err == nil || err.Error() != want {
+			t.Errorf("%s: got error %v, want %q", name, err, want)
+		}
+	}
+}
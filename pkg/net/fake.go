@@ -0,0 +1,56 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// FakeResolver answers lookups from static records, for tests
+type FakeResolver struct {
+	// Hosts are the addresses of each host
+	Hosts map[string][]string
+	// SRV are the targets of each SRV name
+	SRV map[string][]string
+	// Errors are returned for lookups of these names instead of a result
+	Errors map[string]error
+}
+
+// LookupHost returns the addresses of a host
+func (f FakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err, ok := f.Errors[host]; ok {
+		return nil, err
+	}
+	addresses, ok := f.Hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host")
+	}
+	return addresses, nil
+}
+
+// LookupSRV returns the SRV records of a name. Service and proto are
+// prepended to the name as _service._proto if set
+func (f FakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	if service != "" || proto != "" {
+		name = "_" + service + "._" + proto + "." + name
+	}
+	if err, ok := f.Errors[name]; ok {
+		return "", nil, err
+	}
+	targets, ok := f.SRV[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no such host")
+	}
+
+	var records []*net.SRV
+	for _, target := range targets {
+		records = append(records, &net.SRV{Target: target + ".", Port: 6443})
+	}
+	return name, records, nil
+}
@@ -1,19 +1,51 @@
 package net
 
 import (
-	"log"
 	"net"
 )
 
-//GetOutboundIP Get preferred outbound ip of this machine
-func GetOutboundIP() string {
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+// The addresses dialed to find the outbound address of each IP family, unless
+// a probe target is given. Dialing UDP only picks a route, nothing is sent
+const (
+	ipv4ProbeTarget = "8.8.8.8:80"
+	ipv6ProbeTarget = "[2001:4860:4860::8888]:80"
+)
+
+// GetOutboundIP returns the preferred outbound IP of this machine, using the
+// route to target. Without a target, IPv4 is preferred and IPv6 is used if
+// the machine only has an IPv6 route
+func GetOutboundIP(target string) (string, error) {
+	if target != "" {
+		return outboundIP("udp", target)
+	}
+
+	ip, err := outboundIP("udp4", ipv4ProbeTarget)
+	if err == nil {
+		return ip, nil
+	}
+	if ip, err := outboundIP("udp6", ipv6ProbeTarget); err == nil {
+		return ip, nil
+	}
+	return "", err
+}
+
+// GetOutboundIPv6 returns the preferred outbound IPv6 address of this
+// machine, using the route to target if it is an IPv6 address
+func GetOutboundIPv6(target string) (string, error) {
+	if host, _, err := net.SplitHostPort(target); err != nil || net.ParseIP(host) == nil || net.ParseIP(host).To4() != nil {
+		target = ipv6ProbeTarget
+	}
+	return outboundIP("udp6", target)
+}
+
+func outboundIP(network, target string) (string, error) {
+	conn, err := net.Dial(network, target)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	defer conn.Close()
 
 	localAddr := conn.LocalAddr().(*net.UDPAddr)
 
-	return localAddr.IP.String()
+	return localAddr.IP.String(), nil
 }
@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	v "github.com/apptio/kubeadm-bootstrap/pkg/validate"
+)
+
+// Result is the outcome of one rule
+type Result struct {
+	Rule Rule
+	Pass bool
+	// Arg and Value are what the rule checked, including the default. Arg is
+	// empty if the arg wasn't set and there's no default
+	Arg   string
+	Value string
+}
+
+// Report is the outcome of a rule set against a config
+type Report struct {
+	Benchmark string
+	Results   []Result
+}
+
+// Passed returns true if every rule passed
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// Write prints a line per rule and a summary
+func (r Report) Write(w io.Writer) {
+	if len(r.Results) == 0 {
+		fmt.Fprintf(w, "Benchmark %s: no rules apply to this config\n", r.Benchmark)
+		return
+	}
+
+	failed := 0
+	for _, result := range r.Results {
+		status := "PASS"
+		if !result.Pass {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "[%s] %-8s %-6s %s", status, result.Rule.ID, result.Rule.Severity, result.Rule.Description)
+		if !result.Pass {
+			if result.Arg == "" {
+				fmt.Fprintf(w, " (--%s not set)", result.Rule.Arg)
+			} else {
+				fmt.Fprintf(w, " (--%s=%s)", result.Arg, result.Value)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Benchmark %s: %d passed, %d failed\n", r.Benchmark, len(r.Results)-failed, failed)
+}
+
+// Evaluate checks the rules against a config of one or more JSON or YAML
+// documents. Rules for components the config doesn't configure, such as the
+// control plane in a worker's join config, are skipped
+func (rs RuleSet) Evaluate(data []byte) (Report, error) {
+	report := Report{Benchmark: rs.Benchmark}
+
+	docs, err := v.Parse(data)
+	if err != nil {
+		return report, err
+	}
+
+	components := map[string]map[string]string{}
+	for _, doc := range docs {
+		if m, ok := doc.(map[string]interface{}); ok {
+			componentArgs(m, components)
+		}
+	}
+
+	for _, rule := range rs.Rules {
+		args, ok := components[rule.Component]
+		if !ok {
+			continue
+		}
+		report.Results = append(report.Results, rule.evaluate(args))
+	}
+	return report, nil
+}
+
+// componentArgs finds the args of each component configured by a document
+func componentArgs(doc map[string]interface{}, components map[string]map[string]string) {
+	switch doc["kind"] {
+	case "MasterConfiguration":
+		components[APIServer] = stringMap(doc["apiServerExtraArgs"])
+		components[ControllerManager] = stringMap(doc["controllerManagerExtraArgs"])
+		components[Scheduler] = stringMap(doc["schedulerExtraArgs"])
+		// etcd is local unless endpoints are given
+		etcd := object(doc, "etcd")
+		if endpoints, _ := etcd["endpoints"].([]interface{}); len(endpoints) == 0 {
+			components[Etcd] = stringMap(etcd["extraArgs"])
+		}
+	case "ClusterConfiguration":
+		components[APIServer] = stringMap(object(doc, "apiServer")["extraArgs"])
+		components[ControllerManager] = stringMap(object(doc, "controllerManager")["extraArgs"])
+		components[Scheduler] = stringMap(object(doc, "scheduler")["extraArgs"])
+		if local := object(object(doc, "etcd"), "local"); local != nil {
+			components[Etcd] = stringMap(local["extraArgs"])
+		}
+	}
+}
+
+// object returns a field of a document that is an object, or nil
+func object(doc map[string]interface{}, field string) map[string]interface{} {
+	m, _ := doc[field].(map[string]interface{})
+	return m
+}
+
+// stringMap converts an args object to strings, as hand written YAML may not
+// quote values such as false
+func stringMap(value interface{}) map[string]string {
+	args := map[string]string{}
+	m, _ := value.(map[string]interface{})
+	for k, v := range m {
+		args[k] = fmt.Sprint(v)
+	}
+	return args
+}
+
+// lookup returns the arg that is set and its value, falling back to the
+// aliases and then the default
+func (r Rule) lookup(args map[string]string) (string, string) {
+	for _, arg := range append([]string{r.Arg}, r.Aliases...) {
+		if value, ok := args[arg]; ok {
+			return arg, value
+		}
+	}
+	if r.Default != nil {
+		return r.Arg, *r.Default
+	}
+	return "", ""
+}
+
+// evaluate checks a rule against a component's args
+func (r Rule) evaluate(args map[string]string) Result {
+	arg, value := r.lookup(args)
+	set := arg != ""
+	result := Result{Rule: r, Arg: arg, Value: value}
+
+	switch {
+	case r.Equals != nil:
+		result.Pass = set && value == *r.Equals
+	case r.NotEquals != nil:
+		result.Pass = !set || value != *r.NotEquals
+	case r.Contains != "":
+		result.Pass = set && listContains(value, r.Contains)
+	case r.NotContains != "":
+		result.Pass = !set || !listContains(value, r.NotContains)
+	case r.Set:
+		result.Pass = set && value != ""
+	case r.Unset:
+		result.Pass = !set
+	case r.Min != nil:
+		n, err := strconv.Atoi(value)
+		result.Pass = set && err == nil && n >= *r.Min
+	}
+	return result
+}
+
+// listContains returns true if a comma separated list has an item
+func listContains(list, item string) bool {
+	for _, i := range strings.Split(list, ",") {
+		if strings.TrimSpace(i) == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// results describes each result as its rule ID, whether it passed, and the
+// arg and value checked
+func results(report Report) []string {
+	var r []string
+	for _, result := range report.Results {
+		r = append(r, fmt.Sprintf("%s %t %s=%s", result.Rule.ID, result.Pass, result.Arg, result.Value))
+	}
+	return r
+}
+
+func TestEvaluate(t *testing.T) {
+	rules, err := Load("site", "testdata/policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+		passed bool
+	}{
+		{
+			"v1alpha1 with the old arg names",
+			`{
+  "apiVersion": "kubeadm.k8s.io/v1alpha1",
+  "kind": "MasterConfiguration",
+  "apiServerExtraArgs": {"admission-control": "NamespaceLifecycle, NodeRestriction", "audit-log-maxage": "30"},
+  "controllerManagerExtraArgs": {"address": "127.0.0.1"},
+  "schedulerExtraArgs": {"profiling": "false"},
+  "etcd": {"extraArgs": {"client-cert-auth": "true"}}
+}`,
+			[]string{
+				"site.1 true admission-control=NamespaceLifecycle, NodeRestriction",
+				"site.2 true admission-control=NamespaceLifecycle, NodeRestriction",
+				"site.3 true profiling=false",
+				"site.4 true address=127.0.0.1",
+				"site.5 true audit-log-maxage=30",
+				"site.6 true =",
+				"site.7 true client-cert-auth=true",
+			},
+			true,
+		},
+		{
+			"v1beta2 with defaults and external etcd",
+			`
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: InitConfiguration
+---
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+apiServer:
+  extraArgs:
+    # the arg is used over its alias
+    enable-admission-plugins: AlwaysAdmit
+    admission-control: NodeRestriction
+    basic-auth-file: /etc/kubernetes/users.csv
+    audit-log-maxage: 7
+etcd:
+  external:
+    endpoints: [https://10.0.0.1:2379]
+`,
+			[]string{
+				"site.1 false enable-admission-plugins=AlwaysAdmit",
+				"site.2 false enable-admission-plugins=AlwaysAdmit",
+				"site.3 false profiling=true",
+				"site.4 false bind-address=0.0.0.0",
+				"site.5 false audit-log-maxage=7",
+				"site.6 false basic-auth-file=/etc/kubernetes/users.csv",
+			},
+			false,
+		},
+		{
+			"unset args without defaults",
+			`
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+etcd:
+  local:
+    dataDir: /var/lib/etcd
+`,
+			[]string{
+				"site.1 false =",
+				"site.2 true =",
+				"site.3 false profiling=true",
+				"site.4 false bind-address=0.0.0.0",
+				"site.5 false =",
+				"site.6 true =",
+				"site.7 false =",
+			},
+			false,
+		},
+		{
+			"join config",
+			`
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: JoinConfiguration
+`,
+			nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		report, err := rules.Evaluate([]byte(test.config))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := results(report); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got\n%q\nwant\n%q", test.name, got, test.want)
+		}
+		if report.Passed() != test.passed {
+			t.Errorf("%s: Passed() is %t", test.name, report.Passed())
+		}
+	}
+
+	if _, err := rules.Evaluate([]byte("kind: [")); err == nil {
+		t.Error("expected an error for an invalid config")
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	rules, err := Load("site", "testdata/policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := rules.Evaluate([]byte(`{"kind": "ClusterConfiguration", "scheduler": {"extraArgs": {"profiling": "false"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	report.Write(&buf)
+
+	want := `[FAIL] site.1   high   admission plugins include NodeRestriction (--enable-admission-plugins not set)
+[PASS] site.2   high   admission plugins don't include AlwaysAdmit
+[PASS] site.3   medium profiling is off
+[FAIL] site.4   medium the controller manager doesn't listen everywhere (--bind-address=0.0.0.0)
+[FAIL] site.5   low    audit logs are kept for 30 days (--audit-log-maxage not set)
+[PASS] site.6   high   no basic auth
+Benchmark site: 3 passed, 3 failed
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	Report{Benchmark: "site"}.Write(&buf)
+	if want := "Benchmark site: no rules apply to this config\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,196 @@
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GeertJohan/go.rice"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultBenchmark is the built in rule set used unless another is chosen
+const DefaultBenchmark = "cis-1.6"
+
+// The components whose args rules can check
+const (
+	APIServer         = "apiserver"
+	ControllerManager = "controller-manager"
+	Scheduler         = "scheduler"
+	Etcd              = "etcd"
+)
+
+// severities are the valid rule severities
+var severities = map[string]bool{"high": true, "medium": true, "low": true}
+
+// Rule checks the value of one arg of a control plane component
+type Rule struct {
+	// ID is the benchmark section, e.g. 1.2.1
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Severity    string `yaml:"severity"`
+
+	Component string `yaml:"component"`
+	Arg       string `yaml:"arg"`
+	// Aliases are older names of the arg, used if it isn't set
+	Aliases []string `yaml:"aliases"`
+	// Default is the value kubeadm uses if the arg isn't set
+	Default *string `yaml:"default"`
+
+	// exactly one check is set
+	Equals      *string `yaml:"equals"`
+	NotEquals   *string `yaml:"notEquals"`
+	Contains    string  `yaml:"contains"`
+	NotContains string  `yaml:"notContains"`
+	Set         bool    `yaml:"set"`
+	Unset       bool    `yaml:"unset"`
+	Min         *int    `yaml:"min"`
+
+	// Disabled removes a built in rule with the same ID
+	Disabled bool `yaml:"disabled"`
+}
+
+// RuleSet is a versioned set of rules
+type RuleSet struct {
+	Benchmark string `yaml:"benchmark"`
+	Rules     []Rule `yaml:"rules"`
+}
+
+// Load returns the rules of a benchmark. Built in rule sets can be extended
+// by files in dir with the same benchmark name, where rules replace built in
+// rules with the same ID. Rule sets that aren't built in are read from dir
+func Load(benchmark, dir string) (RuleSet, error) {
+	rs := RuleSet{Benchmark: benchmark}
+	found := false
+
+	box, err := rice.FindBox("../../policies")
+	if err != nil {
+		return rs, err
+	}
+	if content, err := box.Bytes(benchmark + ".yaml"); err == nil {
+		builtin, err := parse(benchmark+".yaml", content)
+		if err != nil {
+			return rs, err
+		}
+		rs.Rules = builtin.Rules
+		found = true
+	}
+
+	files, err := ruleFiles(dir)
+	if err != nil {
+		return rs, err
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return rs, err
+		}
+		local, err := parse(file, content)
+		if err != nil {
+			return rs, err
+		}
+		if local.Benchmark != benchmark {
+			continue
+		}
+		rs.merge(local.Rules)
+		found = true
+	}
+
+	if !found {
+		return rs, fmt.Errorf("no rules found for benchmark %s", benchmark)
+	}
+	return rs, nil
+}
+
+// ruleFiles lists the YAML files in a rules directory, which may not exist
+func ruleFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parse reads and checks a rules file
+func parse(name string, content []byte) (RuleSet, error) {
+	var rs RuleSet
+	if err := yaml.Unmarshal(content, &rs); err != nil {
+		return rs, fmt.Errorf("error reading rules from %s: %v", name, err)
+	}
+	if rs.Benchmark == "" {
+		return rs, fmt.Errorf("no benchmark set in %s", name)
+	}
+	for _, rule := range rs.Rules {
+		if err := rule.validate(); err != nil {
+			return rs, fmt.Errorf("invalid rule %s in %s: %v", rule.ID, name, err)
+		}
+	}
+	return rs, nil
+}
+
+// merge adds rules, replacing or removing existing rules with the same ID
+func (rs *RuleSet) merge(rules []Rule) {
+	for _, rule := range rules {
+		i := 0
+		for i < len(rs.Rules) && rs.Rules[i].ID != rule.ID {
+			i++
+		}
+		switch {
+		case i == len(rs.Rules) && !rule.Disabled:
+			rs.Rules = append(rs.Rules, rule)
+		case i == len(rs.Rules):
+		case rule.Disabled:
+			rs.Rules = append(rs.Rules[:i], rs.Rules[i+1:]...)
+		default:
+			rs.Rules[i] = rule
+		}
+	}
+}
+
+// validate checks a rule is complete and has exactly one check
+func (r Rule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("no id set")
+	}
+	if r.Disabled {
+		return nil
+	}
+
+	switch r.Component {
+	case APIServer, ControllerManager, Scheduler, Etcd:
+	default:
+		return fmt.Errorf("unknown component %q, must be one of: %s", r.Component, strings.Join([]string{APIServer, ControllerManager, Scheduler, Etcd}, ", "))
+	}
+	if r.Arg == "" {
+		return fmt.Errorf("no arg set")
+	}
+	if !severities[r.Severity] {
+		return fmt.Errorf("unknown severity %q, must be one of: high, medium, low", r.Severity)
+	}
+
+	checks := 0
+	for _, set := range []bool{r.Equals != nil, r.NotEquals != nil, r.Contains != "", r.NotContains != "", r.Set, r.Unset, r.Min != nil} {
+		if set {
+			checks++
+		}
+	}
+	if checks != 1 {
+		return fmt.Errorf("must have exactly one of equals, notEquals, contains, notContains, set, unset or min")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ruleIDs(rs RuleSet) []string {
+	var ids []string
+	for _, rule := range rs.Rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func TestLoad(t *testing.T) {
+	builtin, err := Load(DefaultBenchmark, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builtin.Rules) == 0 || builtin.Rules[0].ID != "1.2.1" || builtin.Rules[1].ID != "1.2.2" {
+		t.Fatalf("unexpected built in rules %v", ruleIDs(builtin))
+	}
+
+	// a policy dir that doesn't exist is fine
+	missing, err := Load(DefaultBenchmark, "testdata/missing")
+	if err != nil || !reflect.DeepEqual(missing, builtin) {
+		t.Errorf("got %v, %v without a policy dir", ruleIDs(missing), err)
+	}
+
+	// rules in the policy dir replace and disable rules by ID, and are added
+	// after the built in ones
+	merged, err := Load(DefaultBenchmark, "testdata/policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append([]string{"1.2.1"}, ruleIDs(builtin)[2:]...)
+	want = append(want, "local.1")
+	if got := ruleIDs(merged); !reflect.DeepEqual(got, want) {
+		t.Errorf("got rules %v, want %v", got, want)
+	}
+	if rule := merged.Rules[0]; rule.Severity != "low" || rule.Default != nil || *rule.Equals != "true" {
+		t.Errorf("rule 1.2.1 wasn't replaced: %+v", rule)
+	}
+	if !reflect.DeepEqual(merged.Rules[1:len(merged.Rules)-1], builtin.Rules[2:]) {
+		t.Error("built in rules were changed")
+	}
+
+	// rule sets that aren't built in come from the policy dir
+	site, err := Load("site", "testdata/policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ruleIDs(site); !reflect.DeepEqual(got, []string{"site.1", "site.2", "site.3", "site.4", "site.5", "site.6", "site.7"}) {
+		t.Errorf("got rules %v for the site benchmark", got)
+	}
+
+	if _, err := Load("cis-0.1", "testdata/policies"); err == nil || err.Error() != "no rules found for benchmark cis-0.1" {
+		t.Errorf("unexpected error %v for an unknown benchmark", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		// want is the start of the error, where %s is the file
+		want string
+	}{
+		{"benchmark: [", "error reading rules from %s"},
+		{"rules: []", "no benchmark set in %s"},
+		{"benchmark: site\nrules:\n- component: apiserver", "invalid rule  in %s: no id set"},
+		{
+			"benchmark: site\nrules:\n- {id: a, component: kubelet, arg: anonymous-auth, set: true, severity: high}",
+			`invalid rule a in %s: unknown component "kubelet", must be one of: apiserver, controller-manager, scheduler, etcd`,
+		},
+		{
+			"benchmark: site\nrules:\n- {id: a, component: apiserver, set: true, severity: high}",
+			"invalid rule a in %s: no arg set",
+		},
+		{
+			"benchmark: site\nrules:\n- {id: a, component: apiserver, arg: profiling, set: true, severity: critical}",
+			`invalid rule a in %s: unknown severity "critical", must be one of: high, medium, low`,
+		},
+		{
+			"benchmark: site\nrules:\n- {id: a, component: apiserver, arg: profiling, severity: low}",
+			"invalid rule a in %s: must have exactly one of",
+		},
+		{
+			"benchmark: site\nrules:\n- {id: a, component: apiserver, arg: profiling, set: true, min: 1, severity: low}",
+			"invalid rule a in %s: must have exactly one of",
+		},
+	}
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "policies")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "rules.yaml")
+		if err := ioutil.WriteFile(file, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Load("site", dir)
+		if want := strings.Replace(test.want, "%s", file, 1); err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%q: got error %v, want %s", test.content, err, want)
+		}
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,41 @@
+package policy
+
+import (
+	"github.com/GeertJohan/go.rice/embedded"
+	"time"
+)
+
+func init() {
+
+	// define files
+	file2 := &embedded.EmbeddedFile{
+		Filename:    "cis-1.6.yaml",
+		FileModTime: time.Unix(1792294416, 0),
+		Content:     string("# CIS Kubernetes Benchmark v1.6.0, the control plane checks that can be\n# verified from the kubeadm config.\n#\n# Each rule checks one arg of a component: apiserver, controller-manager,\n# scheduler or etcd (local etcd only). aliases are older names of the arg,\n# used if it isn't set. default is the value kubeadm uses when the arg isn't\n# in the config. A rule has one check: equals, notEquals, contains and\n# notContains (an item of a comma separated list), set, unset, or min.\nbenchmark: cis-1.6\nrules:\n\n# 1.2 API Server\n- id: 1.2.1\n  description: Ensure that the --anonymous-auth argument is set to false\n  component: apiserver\n  arg: anonymous-auth\n  default: \"true\"\n  equals: \"false\"\n  severity: medium\n- id: 1.2.2\n  description: Ensure that the --basic-auth-file argument is not set\n  component: apiserver\n  arg: basic-auth-file\n  unset: true\n  severity: high\n- id: 1.2.3\n  description: Ensure that the --token-auth-file parameter is not set\n  component: apiserver\n  arg: token-auth-file\n  unset: true\n  severity: high\n- id: 1.2.4\n  description: Ensure that the --kubelet-https argument is set to true\n  component: apiserver\n  arg: kubelet-https\n  default: \"true\"\n  equals: \"true\"\n  severity: high\n- id: 1.2.7\n  description: Ensure that the --authorization-mode argument is not set to AlwaysAllow\n  component: apiserver\n  arg: authorization-mode\n  default: Node,RBAC\n  notContains: AlwaysAllow\n  severity: high\n- id: 1.2.8\n  description: Ensure that the --authorization-mode argument includes Node\n  component: apiserver\n  arg: authorization-mode\n  default: Node,RBAC\n  contains: Node\n  severity: high\n- id: 1.2.9\n  description: Ensure that the --authorization-mode argument includes RBAC\n  component: apiserver\n  arg: authorization-mode\n  default: Node,RBAC\n  contains: RBAC\n  severity: high\n- id: 1.2.10\n  description: Ensure that the admission control plugin EventRateLimit is set\n  component: apiserver\n  arg: enable-admission-plugins\n  aliases: [admission-control]\n  default: NodeRestriction\n  contains: EventRateLimit\n  severity: low\n- id: 1.2.11\n  description: Ensure that the admission control plugin AlwaysAdmit is not set\n  component: apiserver\n  arg: enable-admission-plugins\n  aliases: [admission-control]\n  default: NodeRestriction\n  notContains: AlwaysAdmit\n  severity: high\n- id: 1.2.12\n  description: Ensure that the admission control plugin AlwaysPullImages is set\n  component: apiserver\n  arg: enable-admission-plugins\n  aliases: [admission-control]\n  default: NodeRestriction\n  contains: AlwaysPullImages\n  severity: medium\n- id: 1.2.16\n  description: Ensure that the admission control plugin PodSecurityPolicy is set\n  component: apiserver\n  arg: enable-admission-plugins\n  aliases: [admission-control]\n  default: NodeRestriction\n  contains: PodSecurityPolicy\n  severity: high\n- id: 1.2.17\n  description: Ensure that the admission control plugin NodeRestriction is set\n  component: apiserver\n  arg: enable-admission-plugins\n  aliases: [admission-control]\n  default: NodeRestriction\n  contains: NodeRestriction\n  severity: high\n- id: 1.2.18\n  description: Ensure that the --insecure-bind-address argument is not set\n  component: apiserver\n  arg: insecure-bind-address\n  unset: true\n  severity: high\n- id: 1.2.19\n  description: Ensure that the --insecure-port argument is set to 0\n  component: apiserver\n  arg: insecure-port\n  default: \"0\"\n  equals: \"0\"\n  severity: high\n- id: 1.2.20\n  description: Ensure that the --secure-port argument is not set to 0\n  component: apiserver\n  arg: secure-port\n  default: \"6443\"\n  notEquals: \"0\"\n  severity: high\n- id: 1.2.21\n  description: Ensure that the --profiling argument is set to false\n  component: apiserver\n  arg: profiling\n  default: \"true\"\n  equals: \"false\"\n  severity: low\n- id: 1.2.22\n  description: Ensure that the --audit-log-path argument is set\n  component: apiserver\n  arg: audit-log-path\n  set: true\n  severity: medium\n- id: 1.2.23\n  description: Ensure that the --audit-log-maxage argument is set to 30 or as appropriate\n  component: apiserver\n  arg: audit-log-maxage\n  min: 30\n  severity: medium\n- id: 1.2.24\n  description: Ensure that the --audit-log-maxbackup argument is set to 10 or as appropriate\n  component: apiserver\n  arg: audit-log-maxbackup\n  min: 10\n  severity: medium\n- id: 1.2.25\n  description: Ensure that the --audit-log-maxsize argument is set to 100 or as appropriate\n  component: apiserver\n  arg: audit-log-maxsize\n  min: 100\n  severity: medium\n- id: 1.2.27\n  description: Ensure that the --service-account-lookup argument is set to true\n  component: apiserver\n  arg: service-account-lookup\n  default: \"true\"\n  equals: \"true\"\n  severity: medium\n\n# 1.3 Controller Manager\n- id: 1.3.1\n  description: Ensure that the --terminated-pod-gc-threshold argument is set as appropriate\n  component: controller-manager\n  arg: terminated-pod-gc-threshold\n  set: true\n  severity: low\n- id: 1.3.2\n  description: Ensure that the --profiling argument is set to false\n  component: controller-manager\n  arg: profiling\n  default: \"true\"\n  equals: \"false\"\n  severity: low\n- id: 1.3.3\n  description: Ensure that the --use-service-account-credentials argument is set to true\n  component: controller-manager\n  arg: use-service-account-credentials\n  default: \"true\"\n  equals: \"true\"\n  severity: medium\n- id: 1.3.7\n  description: Ensure that the --bind-address argument is set to 127.0.0.1\n  component: controller-manager\n  arg: bind-address\n  aliases: [address]\n  default: 127.0.0.1\n  equals: 127.0.0.1\n  severity: medium\n\n# 1.4 Scheduler\n- id: 1.4.1\n  description: Ensure that the --profiling argument is set to false\n  component: scheduler\n  arg: profiling\n  default: \"true\"\n  equals: \"false\"\n  severity: low\n- id: 1.4.2\n  description: Ensure that the --bind-address argument is set to 127.0.0.1\n  component: scheduler\n  arg: bind-address\n  aliases: [address]\n  default: 127.0.0.1\n  equals: 127.0.0.1\n  severity: medium\n\n# 2 etcd\n- id: \"2.2\"\n  description: Ensure that the --client-cert-auth argument is set to true\n  component: etcd\n  arg: client-cert-auth\n  default: \"true\"\n  equals: \"true\"\n  severity: high\n- id: \"2.3\"\n  description: Ensure that the --auto-tls argument is not set to true\n  component: etcd\n  arg: auto-tls\n  default: \"false\"\n  notEquals: \"true\"\n  severity: high\n- id: \"2.5\"\n  description: Ensure that the --peer-client-cert-auth argument is set to true\n  component: etcd\n  arg: peer-client-cert-auth\n  default: \"true\"\n  equals: \"true\"\n  severity: high\n- id: \"2.6\"\n  description: Ensure that the --peer-auto-tls argument is not set to true\n  component: etcd\n  arg: peer-auto-tls\n  default: \"false\"\n  notEquals: \"true\"\n  severity: high\n"),
+	}
+
+	// define dirs
+	dir1 := &embedded.EmbeddedDir{
+		Filename:   "",
+		DirModTime: time.Unix(1792294416, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file2, // "cis-1.6.yaml"
+
+		},
+	}
+
+	// link ChildDirs
+	dir1.ChildDirs = []*embedded.EmbeddedDir{}
+
+	// register embeddedBox
+	embedded.RegisterEmbeddedBox(`../../policies`, &embedded.EmbeddedBox{
+		Name: `../../policies`,
+		Time: time.Unix(1792294416, 0),
+		Dirs: map[string]*embedded.EmbeddedDir{
+			"": dir1,
+		},
+		Files: map[string]*embedded.EmbeddedFile{
+			"cis-1.6.yaml": file2,
+		},
+	})
+}
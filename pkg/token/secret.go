@@ -0,0 +1,198 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	k "github.com/apptio/kubeadm-bootstrap/pkg/kube"
+)
+
+const (
+	// Namespace is where the cluster keeps bootstrap tokens
+	Namespace = "kube-system"
+	// SecretType is the type of bootstrap token Secrets
+	SecretType = "bootstrap.kubernetes.io/token"
+	// SecretPrefix is the name of a bootstrap token Secret, before the token ID
+	SecretPrefix = "bootstrap-token-"
+)
+
+// tokenIDRe matches the public part of a bootstrap token
+var tokenIDRe = regexp.MustCompile(`^[a-z0-9]{6}$`)
+
+// BootstrapToken is a bootstrap token as the cluster stores it
+type BootstrapToken struct {
+	Token string
+	// Expires is when the token stops being valid, or zero if it never does
+	Expires     time.Time
+	Usages      []string
+	Groups      []string
+	Description string
+}
+
+// NewBootstrapToken creates a bootstrap token with options, its TTL
+// starting now. The options must have been validated
+func NewBootstrapToken(token string, opts Options, now time.Time) BootstrapToken {
+	b := BootstrapToken{
+		Token:       token,
+		Usages:      opts.Usages,
+		Groups:      opts.Groups,
+		Description: opts.Description,
+	}
+	if opts.TTL > 0 {
+		b.Expires = now.Add(opts.TTL).UTC().Truncate(time.Second)
+	}
+	return b
+}
+
+// ID returns the public part of the token
+func (b BootstrapToken) ID() string {
+	return ID(b.Token)
+}
+
+// ID returns the public part of a token, or the token itself if it is
+// already an ID
+func ID(token string) string {
+	return strings.SplitN(token, ".", 2)[0]
+}
+
+// Secret encodes a bootstrap token the way the bootstrap authenticator and
+// kubeadm expect
+func (b BootstrapToken) Secret() k.Secret {
+	parts := strings.SplitN(b.Token, ".", 2)
+	data := map[string][]byte{
+		"token-id":     []byte(parts[0]),
+		"token-secret": []byte(parts[1]),
+	}
+	if !b.Expires.IsZero() {
+		data["expiration"] = []byte(b.Expires.UTC().Format(time.RFC3339))
+	}
+	for _, usage := range b.Usages {
+		data["usage-bootstrap-"+usage] = []byte("true")
+	}
+	if len(b.Groups) > 0 {
+		data["auth-extra-groups"] = []byte(strings.Join(b.Groups, ","))
+	}
+	if b.Description != "" {
+		data["description"] = []byte(b.Description)
+	}
+
+	return k.Secret{
+		Metadata: k.ObjectMeta{Name: SecretPrefix + parts[0], Namespace: Namespace},
+		Type:     SecretType,
+		Data:     data,
+	}
+}
+
+// FromSecret decodes a bootstrap token Secret
+func FromSecret(s k.Secret) (BootstrapToken, error) {
+	token := string(s.Data["token-id"]) + "." + string(s.Data["token-secret"])
+	if s.Type != SecretType || !Valid(token) || s.Metadata.Name != SecretPrefix+ID(token) {
+		return BootstrapToken{}, fmt.Errorf("secret %s isn't a valid bootstrap token", s.Metadata.Name)
+	}
+
+	b := BootstrapToken{
+		Token:       token,
+		Description: string(s.Data["description"]),
+	}
+	if expiration := string(s.Data["expiration"]); expiration != "" {
+		expires, err := time.Parse(time.RFC3339, expiration)
+		if err != nil {
+			return BootstrapToken{}, fmt.Errorf("secret %s has an invalid expiration %s", s.Metadata.Name, expiration)
+		}
+		b.Expires = expires
+	}
+	for _, usage := range Usages {
+		if string(s.Data["usage-bootstrap-"+usage]) == "true" {
+			b.Usages = append(b.Usages, usage)
+		}
+	}
+	if groups := string(s.Data["auth-extra-groups"]); groups != "" {
+		b.Groups = strings.Split(groups, ",")
+	}
+	return b, nil
+}
+
+// Create stores a bootstrap token in the cluster
+func Create(ctx context.Context, c k.SecretClient, b BootstrapToken) error {
+	if !Valid(b.Token) {
+		return fmt.Errorf("invalid bootstrap token %q, must match [a-z0-9]{6}.[a-z0-9]{16}", b.Token)
+	}
+	if err := c.CreateSecret(ctx, b.Secret()); err != nil {
+		if k.IsAlreadyExists(err) {
+			return fmt.Errorf("bootstrap token %s already exists", b.ID())
+		}
+		return err
+	}
+	return nil
+}
+
+// Get returns a bootstrap token of the cluster by its ID, or the whole token
+func Get(ctx context.Context, c k.SecretClient, id string) (BootstrapToken, error) {
+	id = ID(id)
+	if !tokenIDRe.MatchString(id) {
+		return BootstrapToken{}, fmt.Errorf("invalid bootstrap token ID %q, must match [a-z0-9]{6}", id)
+	}
+
+	s, err := c.GetSecret(ctx, Namespace, SecretPrefix+id)
+	if k.IsNotFound(err) {
+		return BootstrapToken{}, fmt.Errorf("bootstrap token %s not found", id)
+	}
+	if err != nil {
+		return BootstrapToken{}, err
+	}
+	return FromSecret(s)
+}
+
+// List returns the bootstrap tokens of the cluster, sorted by ID. Secrets that
+// aren't valid bootstrap tokens are skipped
+func List(ctx context.Context, c k.SecretClient) ([]BootstrapToken, error) {
+	secrets, err := c.ListSecrets(ctx, Namespace, SecretType)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []BootstrapToken
+	for _, s := range secrets {
+		b, err := FromSecret(s)
+		if err != nil {
+			log.Warn("Skipping ", err)
+			continue
+		}
+		tokens = append(tokens, b)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Token < tokens[j].Token })
+	return tokens, nil
+}
+
+// Delete removes a bootstrap token from the cluster by its ID, or the whole
+// token
+func Delete(ctx context.Context, c k.SecretClient, id string) error {
+	id = ID(id)
+	if !tokenIDRe.MatchString(id) {
+		return fmt.Errorf("invalid bootstrap token ID %q, must match [a-z0-9]{6}", id)
+	}
+
+	err := c.DeleteSecret(ctx, Namespace, SecretPrefix+id)
+	if k.IsNotFound(err) {
+		return fmt.Errorf("bootstrap token %s not found", id)
+	}
+	return err
+}
+
+// Rotate replaces a bootstrap token with another. The replacement is created
+// first, so there is always a token nodes can join with
+func Rotate(ctx context.Context, c k.SecretClient, id string, replacement BootstrapToken) error {
+	if err := Create(ctx, c, replacement); err != nil {
+		return err
+	}
+	if err := Delete(ctx, c, id); err != nil {
+		return fmt.Errorf("created bootstrap token %s, but couldn't delete %s: %v", replacement.ID(), ID(id), err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package token
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	k "github.com/apptio/kubeadm-bootstrap/pkg/kube"
+)
+
+// recordingClient records the calls made to a fake client
+type recordingClient struct {
+	*k.FakeClient
+	calls []string
+}
+
+func (r *recordingClient) CreateSecret(ctx context.Context, secret k.Secret) error {
+	r.calls = append(r.calls, "create "+secret.Metadata.Name)
+	return r.FakeClient.CreateSecret(ctx, secret)
+}
+
+func (r *recordingClient) DeleteSecret(ctx context.Context, namespace, name string) error {
+	r.calls = append(r.calls, "delete "+name)
+	return r.FakeClient.DeleteSecret(ctx, namespace, name)
+}
+
+func testToken(token string) BootstrapToken {
+	return BootstrapToken{
+		Token:       token,
+		Expires:     time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Usages:      []string{"signing", "authentication"},
+		Groups:      []string{DefaultGroup},
+		Description: "test token",
+	}
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	tokens := map[string]BootstrapToken{
+		"all fields": testToken("abcdef.0123456789abcdef"),
+		"no expiry":  {Token: "ghijkl.0123456789abcdef", Usages: []string{"signing"}},
+	}
+
+	for name, b := range tokens {
+		s := b.Secret()
+		if s.Metadata.Name != SecretPrefix+b.ID() || s.Metadata.Namespace != Namespace || s.Type != SecretType {
+			t.Errorf("%s: unexpected secret %s/%s of type %s", name, s.Metadata.Namespace, s.Metadata.Name, s.Type)
+		}
+
+		got, err := FromSecret(s)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, b) {
+			t.Errorf("%s: got %+v, want %+v", name, got, b)
+		}
+	}
+}
+
+func TestFromSecretInvalid(t *testing.T) {
+	valid := testToken("abcdef.0123456789abcdef").Secret()
+
+	wrongType := valid
+	wrongType.Type = "Opaque"
+
+	wrongName := valid
+	wrongName.Metadata.Name = SecretPrefix + "zzzzzz"
+
+	badExpiry := testToken("abcdef.0123456789abcdef").Secret()
+	badExpiry.Data["expiration"] = []byte("tomorrow")
+
+	for name, s := range map[string]k.Secret{"type": wrongType, "name": wrongName, "expiration": badExpiry} {
+		if _, err := FromSecret(s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCreateListDelete(t *testing.T) {
+	ctx := context.Background()
+	c := k.NewFakeClient(
+		// not a bootstrap token, so List skips it
+		k.Secret{Metadata: k.ObjectMeta{Name: SecretPrefix + "broken", Namespace: Namespace}, Type: SecretType},
+	)
+
+	for _, token := range []string{"zyxwvu.0123456789abcdef", "abcdef.0123456789abcdef"} {
+		if err := Create(ctx, c, testToken(token)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tokens, err := List(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0].ID() != "abcdef" || tokens[1].ID() != "zyxwvu" {
+		t.Fatalf("unexpected tokens %+v", tokens)
+	}
+
+	got, err := Get(ctx, c, "abcdef.0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testToken("abcdef.0123456789abcdef")) {
+		t.Errorf("got %+v", got)
+	}
+
+	if err := Delete(ctx, c, "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err = List(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].ID() != "zyxwvu" {
+		t.Errorf("unexpected tokens after delete %+v", tokens)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	c := k.NewFakeClient(testToken("abcdef.0123456789abcdef").Secret())
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"create existing", Create(ctx, c, testToken("abcdef.0123456789abcdef")), "bootstrap token abcdef already exists"},
+		{"create invalid", Create(ctx, c, testToken("abc.def")), "invalid bootstrap token"},
+		{"get missing", func() error { _, err := Get(ctx, c, "ghijkl"); return err }(), "bootstrap token ghijkl not found"},
+		{"get invalid", func() error { _, err := Get(ctx, c, "ABC"); return err }(), "invalid bootstrap token ID"},
+		{"delete missing", Delete(ctx, c, "ghijkl"), "bootstrap token ghijkl not found"},
+	}
+
+	for _, test := range tests {
+		if test.err == nil || !strings.Contains(test.err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %q", test.name, test.err, test.want)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	c := &recordingClient{FakeClient: k.NewFakeClient(testToken("abcdef.0123456789abcdef").Secret())}
+
+	if err := Rotate(ctx, c, "abcdef", testToken("ghijkl.0123456789abcdef")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"create " + SecretPrefix + "ghijkl", "delete " + SecretPrefix + "abcdef"}
+	if !reflect.DeepEqual(c.calls, want) {
+		t.Errorf("got calls %v, want %v", c.calls, want)
+	}
+
+	tokens, err := List(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].ID() != "ghijkl" {
+		t.Errorf("unexpected tokens after rotate %+v", tokens)
+	}
+}
+
+func TestRotateKeepsReplacement(t *testing.T) {
+	ctx := context.Background()
+	c := k.NewFakeClient()
+
+	// the old token is missing, but the replacement is still created
+	err := Rotate(ctx, c, "abcdef", testToken("ghijkl.0123456789abcdef"))
+	if err == nil || !strings.Contains(err.Error(), "created bootstrap token ghijkl, but couldn't delete abcdef") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := Get(ctx, c, "ghijkl"); err != nil {
+		t.Error(err)
+	}
+
+	// an existing replacement leaves the old token alone
+	if err := Rotate(ctx, c, "ghijkl", testToken("ghijkl.0123456789abcdef")); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := Get(ctx, c, "ghijkl"); err != nil {
+		t.Error(err)
+	}
+}
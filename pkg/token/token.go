@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"crypto/rand"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 )
 
 // This is lifted directly from kubeadm
@@ -17,8 +20,76 @@ const (
 	TokenSecretBytes = 16
 	// valid chars for a bootstrap token
 	validBootstrapTokenChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	// DefaultTTL is how long kubeadm keeps a bootstrap token by default
+	DefaultTTL = 24 * time.Hour
+	// DefaultGroup is the group kubeadm gives bootstrap tokens, which lets
+	// nodes join
+	DefaultGroup = "system:bootstrappers:kubeadm:default-node-token"
+)
+
+var (
+	// bootstrapTokenRe matches a bootstrap token, as kubeadm validates it
+	bootstrapTokenRe = regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)
+	// bootstrapGroupRe matches the extra groups a bootstrap token can have
+	bootstrapGroupRe = regexp.MustCompile(`^system:bootstrappers:[a-z0-9:-]{0,255}[a-z0-9]$`)
 )
 
+// Usages are what a bootstrap token can be used for: signing the cluster-info
+// ConfigMap for discovery, and authenticating to the API server
+var Usages = []string{"signing", "authentication"}
+
+// Options are how a bootstrap token is created
+type Options struct {
+	// TTL is how long the token is valid, 0 never expires
+	TTL time.Duration
+	// Usages default to all of Usages
+	Usages []string
+	// Groups are the extra groups the token authenticates as, defaults to
+	// DefaultGroup
+	Groups      []string
+	Description string
+}
+
+// DefaultOptions are the options kubeadm creates tokens with
+func DefaultOptions() Options {
+	return Options{
+		TTL:    DefaultTTL,
+		Usages: Usages,
+		Groups: []string{DefaultGroup},
+	}
+}
+
+// WithDefaults fills in the default usages and groups if there are none
+func (o Options) WithDefaults() Options {
+	defaults := DefaultOptions()
+	if len(o.Usages) == 0 {
+		o.Usages = defaults.Usages
+	}
+	if len(o.Groups) == 0 {
+		o.Groups = defaults.Groups
+	}
+	return o
+}
+
+// Validate checks the options are ones kubeadm accepts
+func (o Options) Validate() error {
+	if o.TTL < 0 {
+		return fmt.Errorf("invalid token TTL %s, must not be negative", o.TTL)
+	}
+	for _, usage := range o.Usages {
+		if !ValidUsage(usage) {
+			return fmt.Errorf("invalid token usage %q, must be one of: %s", usage, strings.Join(Usages, ", "))
+		}
+	}
+	for _, group := range o.Groups {
+		if !ValidGroup(group) {
+			return fmt.Errorf("invalid token group %q, must match %s", group, bootstrapGroupRe)
+		}
+	}
+	return nil
+}
+
 func randBytes(length int) (string, error) {
 	// len("0123456789abcdefghijklmnopqrstuvwxyz") = 36 which doesn't evenly divide
 	// the possible values of a byte: 256 mod 36 = 4. Discard any random bytes we
@@ -64,3 +135,23 @@ func GenerateToken() (string, error) {
 
 	return fmt.Sprintf("%s.%s", tokenID, tokenSecret), nil
 }
+
+// Valid returns true if a token is a well formed bootstrap token
+func Valid(token string) bool {
+	return bootstrapTokenRe.MatchString(token)
+}
+
+// ValidUsage returns true if a bootstrap token can be used for usage
+func ValidUsage(usage string) bool {
+	for _, u := range Usages {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidGroup returns true if a bootstrap token can authenticate as group
+func ValidGroup(group string) bool {
+	return bootstrapGroupRe.MatchString(group)
+}
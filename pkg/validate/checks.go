@@ -0,0 +1,178 @@
+package validate
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	t "github.com/apptio/kubeadm-bootstrap/pkg/token"
+)
+
+var (
+	// dnsNameRe matches a lowercase RFC 1123 subdomain, which is what kubeadm
+	// accepts as a certificate SAN
+	dnsNameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// ipLikeRe matches SANs that can only be meant as IP addresses
+	ipLikeRe = regexp.MustCompile(`^[0-9.]+$|:`)
+	// caCertHashRe matches a discovery token CA cert hash
+	caCertHashRe = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+)
+
+// integerArgs are the control plane args that take a number, and their minimum
+var integerArgs = map[string]int{
+	"apiserver-count":             1,
+	"audit-log-maxage":            0,
+	"audit-log-maxbackup":         0,
+	"audit-log-maxsize":           0,
+	"terminated-pod-gc-threshold": 0,
+}
+
+// token checks a bootstrap token
+func (c *checker) token(path, token string) {
+	if !t.Valid(token) {
+		c.errorf(path, "invalid bootstrap token %q, must match [a-z0-9]{6}.[a-z0-9]{16}", token)
+	}
+}
+
+// tokenUsages checks what bootstrap tokens can be used for
+func (c *checker) tokenUsages(path string, usages []string) {
+	for i, usage := range usages {
+		if !t.ValidUsage(usage) {
+			c.errorf(index(path, i), "invalid token usage %q, must be one of: %s", usage, strings.Join(t.Usages, ", "))
+		}
+	}
+}
+
+// tokenGroups checks the extra groups of bootstrap tokens
+func (c *checker) tokenGroups(path string, groups []string) {
+	for i, group := range groups {
+		if !t.ValidGroup(group) {
+			c.errorf(index(path, i), "invalid token group %q, must start with system:bootstrappers:", group)
+		}
+	}
+}
+
+// ip checks an IP address
+func (c *checker) ip(path, ip string) {
+	if net.ParseIP(ip) == nil {
+		c.errorf(path, "invalid IP address %q", ip)
+	}
+}
+
+// cidr checks a comma separated list of subnets
+func (c *checker) cidr(path, cidrs string) {
+	for _, cidr := range strings.Split(cidrs, ",") {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			c.errorf(path, "invalid CIDR %q", cidr)
+		}
+	}
+}
+
+// port checks a port, where 0 means the default
+func (c *checker) port(path string, port int) {
+	if port < 0 || port > 65535 {
+		c.errorf(path, "invalid port %d", port)
+	}
+}
+
+// duration checks a duration such as a token TTL
+func (c *checker) duration(path, d string) {
+	if _, err := time.ParseDuration(d); err != nil {
+		c.errorf(path, "invalid duration %q", d)
+	}
+}
+
+// hostPort checks an address of a host and a port, where the port may be
+// optional
+func (c *checker) hostPort(path, address string, portRequired bool) {
+	host := address
+	if portRequired || strings.Contains(address, ":") {
+		h, port, err := net.SplitHostPort(address)
+		if err != nil {
+			c.errorf(path, "invalid address %q, must be host:port", address)
+			return
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			c.errorf(path, "invalid port in %q", address)
+		}
+		host = h
+	}
+	if host == "" {
+		c.errorf(path, "no host in %q", address)
+	}
+}
+
+// certSANs checks that each SAN is an IP address or a DNS name
+func (c *checker) certSANs(path string, sans []string) {
+	for i, san := range sans {
+		p := index(path, i)
+		switch {
+		case san == "":
+			c.errorf(p, "must not be empty")
+		case ipLikeRe.MatchString(san):
+			c.ip(p, san)
+		case !dnsNameRe.MatchString(strings.TrimPrefix(san, "*.")):
+			c.errorf(p, "invalid DNS name %q, must be lowercase letters, digits, '-' and '.'", san)
+		}
+	}
+}
+
+// extraArgs checks the args passed to a control plane component
+func (c *checker) extraArgs(path string, args map[string]string) {
+	var names []string
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, value := join(path, name), args[name]
+		if value == "" {
+			c.errorf(p, "must not be empty")
+			continue
+		}
+		if min, ok := integerArgs[name]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n < min {
+				c.errorf(p, "must be an integer of at least %d, got %q", min, value)
+			}
+		}
+	}
+}
+
+// externalEtcd checks the endpoints and TLS files of an external etcd
+// cluster. Endpoints must use https when any TLS file is set
+func (c *checker) externalEtcd(path string, endpoints []string, caFile, certFile, keyFile string) {
+	if len(endpoints) == 0 {
+		c.errorf(join(path, "endpoints"), "at least one endpoint is required")
+	}
+
+	tls := caFile != "" || certFile != "" || keyFile != ""
+	for i, endpoint := range endpoints {
+		p := index(join(path, "endpoints"), i)
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			c.errorf(p, "invalid URL %q", endpoint)
+			continue
+		}
+		if tls && u.Scheme != "https" {
+			c.errorf(p, "must use https when etcd TLS files are set, got %q", endpoint)
+		}
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		c.errorf(path, "certFile and keyFile must be set together")
+	}
+}
+
+// caCertHashes checks discovery token CA cert hashes
+func (c *checker) caCertHashes(path string, hashes []string) {
+	for i, hash := range hashes {
+		if !caCertHashRe.MatchString(hash) {
+			c.errorf(index(path, i), "invalid CA cert hash %q, must be sha256:<hex>", hash)
+		}
+	}
+}
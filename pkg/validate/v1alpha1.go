@@ -0,0 +1,162 @@
+package validate
+
+// kubeadm.k8s.io/v1alpha1 (kubeadm 1.8 - 1.10)
+
+func init() {
+	register("kubeadm.k8s.io/v1alpha1", map[string]func() document{
+		"MasterConfiguration": func() document { return &masterConfigurationV1alpha1{} },
+		"NodeConfiguration":   func() document { return &nodeConfigurationV1alpha1{} },
+	})
+}
+
+// typeMeta is the apiVersion and kind every document has
+type typeMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+type masterConfigurationV1alpha1 struct {
+	typeMeta
+	API struct {
+		AdvertiseAddress     string `json:"advertiseAddress"`
+		ControlPlaneEndpoint string `json:"controlPlaneEndpoint"`
+		BindPort             int    `json:"bindPort"`
+	} `json:"api"`
+	KubeProxy struct {
+		Config interface{} `json:"config"`
+	} `json:"kubeProxy"`
+	Etcd struct {
+		Endpoints      []string          `json:"endpoints"`
+		CAFile         string            `json:"caFile"`
+		CertFile       string            `json:"certFile"`
+		KeyFile        string            `json:"keyFile"`
+		DataDir        string            `json:"dataDir"`
+		ExtraArgs      map[string]string `json:"extraArgs"`
+		Image          string            `json:"image"`
+		SelfHosted     interface{}       `json:"selfHosted"`
+		ServerCertSANs []string          `json:"serverCertSANs"`
+		PeerCertSANs   []string          `json:"peerCertSANs"`
+	} `json:"etcd"`
+	KubeletConfiguration struct {
+		BaseConfig interface{} `json:"baseConfig"`
+	} `json:"kubeletConfiguration"`
+	Networking struct {
+		ServiceSubnet string `json:"serviceSubnet"`
+		PodSubnet     string `json:"podSubnet"`
+		DNSDomain     string `json:"dnsDomain"`
+	} `json:"networking"`
+	KubernetesVersion             string                  `json:"kubernetesVersion"`
+	CloudProvider                 string                  `json:"cloudProvider"`
+	NodeName                      string                  `json:"nodeName"`
+	AuthorizationModes            []string                `json:"authorizationModes"`
+	NoTaintMaster                 bool                    `json:"noTaintMaster"`
+	Token                         string                  `json:"token"`
+	TokenTTL                      string                  `json:"tokenTTL"`
+	TokenUsages                   []string                `json:"tokenUsages"`
+	TokenGroups                   []string                `json:"tokenGroups"`
+	CRISocket                     string                  `json:"criSocket"`
+	APIServerExtraArgs            map[string]string       `json:"apiServerExtraArgs"`
+	ControllerManagerExtraArgs    map[string]string       `json:"controllerManagerExtraArgs"`
+	SchedulerExtraArgs            map[string]string       `json:"schedulerExtraArgs"`
+	APIServerExtraVolumes         []hostPathMountV1alpha1 `json:"apiServerExtraVolumes"`
+	ControllerManagerExtraVolumes []hostPathMountV1alpha1 `json:"controllerManagerExtraVolumes"`
+	SchedulerExtraVolumes         []hostPathMountV1alpha1 `json:"schedulerExtraVolumes"`
+	APIServerCertSANs             []string                `json:"apiServerCertSANs"`
+	CertificatesDir               string                  `json:"certificatesDir"`
+	ImageRepository               string                  `json:"imageRepository"`
+	ImagePullPolicy               string                  `json:"imagePullPolicy"`
+	UnifiedControlPlaneImage      string                  `json:"unifiedControlPlaneImage"`
+	AuditPolicyConfiguration      struct {
+		Path      string `json:"path"`
+		LogDir    string `json:"logDir"`
+		LogMaxAge int    `json:"logMaxAge"`
+	} `json:"auditPolicy"`
+	FeatureGates   map[string]bool `json:"featureGates"`
+	ClusterName    string          `json:"clusterName"`
+	PrivilegedPods bool            `json:"privilegedPods"`
+}
+
+type hostPathMountV1alpha1 struct {
+	Name      string `json:"name"`
+	HostPath  string `json:"hostPath"`
+	MountPath string `json:"mountPath"`
+}
+
+func (m *masterConfigurationV1alpha1) check(c *checker) {
+	if m.Token != "" {
+		c.token("token", m.Token)
+	}
+	if m.TokenTTL != "" && m.TokenTTL != "0" {
+		c.duration("tokenTTL", m.TokenTTL)
+	}
+	c.tokenUsages("tokenUsages", m.TokenUsages)
+	c.tokenGroups("tokenGroups", m.TokenGroups)
+
+	if m.API.AdvertiseAddress != "" {
+		c.ip("api.advertiseAddress", m.API.AdvertiseAddress)
+	}
+	c.port("api.bindPort", m.API.BindPort)
+	if m.API.ControlPlaneEndpoint != "" {
+		c.hostPort("api.controlPlaneEndpoint", m.API.ControlPlaneEndpoint, false)
+	}
+
+	c.certSANs("apiServerCertSANs", m.APIServerCertSANs)
+	c.extraArgs("apiServerExtraArgs", m.APIServerExtraArgs)
+	c.extraArgs("controllerManagerExtraArgs", m.ControllerManagerExtraArgs)
+	c.extraArgs("schedulerExtraArgs", m.SchedulerExtraArgs)
+
+	if m.Networking.ServiceSubnet != "" {
+		c.cidr("networking.serviceSubnet", m.Networking.ServiceSubnet)
+	}
+	if m.Networking.PodSubnet != "" {
+		c.cidr("networking.podSubnet", m.Networking.PodSubnet)
+	}
+
+	// kubeadm runs local etcd unless endpoints are given
+	if len(m.Etcd.Endpoints) > 0 {
+		c.externalEtcd("etcd", m.Etcd.Endpoints, m.Etcd.CAFile, m.Etcd.CertFile, m.Etcd.KeyFile)
+	} else {
+		c.extraArgs("etcd.extraArgs", m.Etcd.ExtraArgs)
+		c.certSANs("etcd.serverCertSANs", m.Etcd.ServerCertSANs)
+		c.certSANs("etcd.peerCertSANs", m.Etcd.PeerCertSANs)
+	}
+}
+
+type nodeConfigurationV1alpha1 struct {
+	typeMeta
+	CACertPath                             string          `json:"caCertPath"`
+	DiscoveryFile                          string          `json:"discoveryFile"`
+	DiscoveryToken                         string          `json:"discoveryToken"`
+	DiscoveryTokenAPIServers               []string        `json:"discoveryTokenAPIServers"`
+	DiscoveryTimeout                       string          `json:"discoveryTimeout"`
+	NodeName                               string          `json:"nodeName"`
+	TLSBootstrapToken                      string          `json:"tlsBootstrapToken"`
+	Token                                  string          `json:"token"`
+	CRISocket                              string          `json:"criSocket"`
+	DiscoveryTokenCACertHashes             []string        `json:"discoveryTokenCACertHashes"`
+	DiscoveryTokenUnsafeSkipCAVerification bool            `json:"discoveryTokenUnsafeSkipCAVerification"`
+	FeatureGates                           map[string]bool `json:"featureGates"`
+}
+
+func (n *nodeConfigurationV1alpha1) check(c *checker) {
+	if n.Token != "" {
+		c.token("token", n.Token)
+	}
+	if n.DiscoveryToken != "" {
+		c.token("discoveryToken", n.DiscoveryToken)
+	}
+	if n.TLSBootstrapToken != "" {
+		c.token("tlsBootstrapToken", n.TLSBootstrapToken)
+	}
+	if n.Token == "" && n.DiscoveryFile == "" && (n.DiscoveryToken == "" || n.TLSBootstrapToken == "") {
+		c.errorf("token", "a token or a discovery file is required")
+	}
+
+	for i, server := range n.DiscoveryTokenAPIServers {
+		c.hostPort(index("discoveryTokenAPIServers", i), server, true)
+	}
+	c.caCertHashes("discoveryTokenCACertHashes", n.DiscoveryTokenCACertHashes)
+	if n.DiscoveryTimeout != "" {
+		c.duration("discoveryTimeout", n.DiscoveryTimeout)
+	}
+}
@@ -0,0 +1,349 @@
+package validate
+
+// kubeadm.k8s.io/v1beta1 (kubeadm 1.13 - 1.14), v1beta2 (kubeadm 1.15 - 1.21)
+// and v1beta3 (kubeadm 1.22+). Each version only adds or removes a few fields,
+// so the parts they share are defined once
+
+func init() {
+	register("kubeadm.k8s.io/v1beta1", map[string]func() document{
+		"InitConfiguration":    func() document { return &initConfigurationV1beta1{} },
+		"ClusterConfiguration": func() document { return &clusterConfigurationV1beta1{} },
+		"JoinConfiguration":    func() document { return &joinConfigurationV1beta1{} },
+	})
+	register("kubeadm.k8s.io/v1beta2", map[string]func() document{
+		"InitConfiguration":    func() document { return &initConfigurationV1beta2{} },
+		"ClusterConfiguration": func() document { return &clusterConfigurationV1beta1{} },
+		"JoinConfiguration":    func() document { return &joinConfigurationV1beta2{} },
+	})
+	register("kubeadm.k8s.io/v1beta3", map[string]func() document{
+		"InitConfiguration":    func() document { return &initConfigurationV1beta3{} },
+		"ClusterConfiguration": func() document { return &clusterConfigurationV1beta3{} },
+		"JoinConfiguration":    func() document { return &joinConfigurationV1beta3{} },
+	})
+}
+
+type apiEndpoint struct {
+	AdvertiseAddress string `json:"advertiseAddress"`
+	BindPort         int    `json:"bindPort"`
+}
+
+func (e apiEndpoint) check(c *checker, path string) {
+	if e.AdvertiseAddress != "" {
+		c.ip(join(path, "advertiseAddress"), e.AdvertiseAddress)
+	}
+	c.port(join(path, "bindPort"), e.BindPort)
+}
+
+type bootstrapToken struct {
+	Token       string   `json:"token"`
+	Description string   `json:"description"`
+	TTL         string   `json:"ttl"`
+	Expires     string   `json:"expires"`
+	Usages      []string `json:"usages"`
+	Groups      []string `json:"groups"`
+}
+
+func (b bootstrapToken) check(c *checker, path string) {
+	c.token(join(path, "token"), b.Token)
+	if b.TTL != "" {
+		c.duration(join(path, "ttl"), b.TTL)
+	}
+	c.tokenUsages(join(path, "usages"), b.Usages)
+	c.tokenGroups(join(path, "groups"), b.Groups)
+}
+
+type taint struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Effect    string `json:"effect"`
+	TimeAdded string `json:"timeAdded"`
+}
+
+type nodeRegistrationV1beta1 struct {
+	Name             string            `json:"name"`
+	CRISocket        string            `json:"criSocket"`
+	Taints           []taint           `json:"taints"`
+	KubeletExtraArgs map[string]string `json:"kubeletExtraArgs"`
+}
+
+func (n nodeRegistrationV1beta1) check(c *checker, path string) {
+	c.extraArgs(join(path, "kubeletExtraArgs"), n.KubeletExtraArgs)
+}
+
+type nodeRegistrationV1beta2 struct {
+	nodeRegistrationV1beta1
+	IgnorePreflightErrors []string `json:"ignorePreflightErrors"`
+}
+
+type nodeRegistrationV1beta3 struct {
+	nodeRegistrationV1beta2
+	ImagePullPolicy string `json:"imagePullPolicy"`
+}
+
+type hostPathMount struct {
+	Name      string `json:"name"`
+	HostPath  string `json:"hostPath"`
+	MountPath string `json:"mountPath"`
+	ReadOnly  bool   `json:"readOnly"`
+	PathType  string `json:"pathType"`
+}
+
+type controlPlaneComponent struct {
+	ExtraArgs    map[string]string `json:"extraArgs"`
+	ExtraVolumes []hostPathMount   `json:"extraVolumes"`
+}
+
+type apiServer struct {
+	controlPlaneComponent
+	CertSANs               []string `json:"certSANs"`
+	TimeoutForControlPlane string   `json:"timeoutForControlPlane"`
+}
+
+type etcd struct {
+	Local *struct {
+		ImageRepository string            `json:"imageRepository"`
+		ImageTag        string            `json:"imageTag"`
+		DataDir         string            `json:"dataDir"`
+		ExtraArgs       map[string]string `json:"extraArgs"`
+		ServerCertSANs  []string          `json:"serverCertSANs"`
+		PeerCertSANs    []string          `json:"peerCertSANs"`
+	} `json:"local"`
+	External *struct {
+		Endpoints []string `json:"endpoints"`
+		CAFile    string   `json:"caFile"`
+		CertFile  string   `json:"certFile"`
+		KeyFile   string   `json:"keyFile"`
+	} `json:"external"`
+}
+
+func (e etcd) check(c *checker, path string) {
+	if e.Local != nil && e.External != nil {
+		c.errorf(path, "local and external etcd can't both be set")
+	}
+	if e.Local != nil {
+		c.extraArgs(join(path, "local.extraArgs"), e.Local.ExtraArgs)
+		c.certSANs(join(path, "local.serverCertSANs"), e.Local.ServerCertSANs)
+		c.certSANs(join(path, "local.peerCertSANs"), e.Local.PeerCertSANs)
+	}
+	if e.External != nil {
+		c.externalEtcd(join(path, "external"), e.External.Endpoints, e.External.CAFile, e.External.CertFile, e.External.KeyFile)
+	}
+}
+
+type networking struct {
+	ServiceSubnet string `json:"serviceSubnet"`
+	PodSubnet     string `json:"podSubnet"`
+	DNSDomain     string `json:"dnsDomain"`
+}
+
+func (n networking) check(c *checker, path string) {
+	if n.ServiceSubnet != "" {
+		c.cidr(join(path, "serviceSubnet"), n.ServiceSubnet)
+	}
+	if n.PodSubnet != "" {
+		c.cidr(join(path, "podSubnet"), n.PodSubnet)
+	}
+}
+
+// clusterConfiguration are the ClusterConfiguration fields every beta version
+// has
+type clusterConfiguration struct {
+	typeMeta
+	Etcd                 etcd                  `json:"etcd"`
+	Networking           networking            `json:"networking"`
+	KubernetesVersion    string                `json:"kubernetesVersion"`
+	ControlPlaneEndpoint string                `json:"controlPlaneEndpoint"`
+	APIServer            apiServer             `json:"apiServer"`
+	ControllerManager    controlPlaneComponent `json:"controllerManager"`
+	Scheduler            controlPlaneComponent `json:"scheduler"`
+	CertificatesDir      string                `json:"certificatesDir"`
+	ImageRepository      string                `json:"imageRepository"`
+	FeatureGates         map[string]bool       `json:"featureGates"`
+	ClusterName          string                `json:"clusterName"`
+}
+
+func (cc *clusterConfiguration) check(c *checker) {
+	if cc.ControlPlaneEndpoint != "" {
+		c.hostPort("controlPlaneEndpoint", cc.ControlPlaneEndpoint, false)
+	}
+	c.certSANs("apiServer.certSANs", cc.APIServer.CertSANs)
+	c.extraArgs("apiServer.extraArgs", cc.APIServer.ExtraArgs)
+	c.extraArgs("controllerManager.extraArgs", cc.ControllerManager.ExtraArgs)
+	c.extraArgs("scheduler.extraArgs", cc.Scheduler.ExtraArgs)
+	cc.Etcd.check(c, "etcd")
+	cc.Networking.check(c, "networking")
+	if cc.APIServer.TimeoutForControlPlane != "" {
+		c.duration("apiServer.timeoutForControlPlane", cc.APIServer.TimeoutForControlPlane)
+	}
+}
+
+type clusterConfigurationV1beta1 struct {
+	clusterConfiguration
+	DNS struct {
+		Type            string `json:"type"`
+		ImageRepository string `json:"imageRepository"`
+		ImageTag        string `json:"imageTag"`
+	} `json:"dns"`
+	UseHyperKubeImage bool `json:"useHyperKubeImage"`
+}
+
+type clusterConfigurationV1beta3 struct {
+	clusterConfiguration
+	DNS struct {
+		ImageRepository string `json:"imageRepository"`
+		ImageTag        string `json:"imageTag"`
+	} `json:"dns"`
+}
+
+type bootstrapTokenDiscovery struct {
+	Token                    string   `json:"token"`
+	APIServerEndpoint        string   `json:"apiServerEndpoint"`
+	CACertHashes             []string `json:"caCertHashes"`
+	UnsafeSkipCAVerification bool     `json:"unsafeSkipCAVerification"`
+}
+
+type discovery struct {
+	BootstrapToken *bootstrapTokenDiscovery `json:"bootstrapToken"`
+	File           *struct {
+		KubeConfigPath string `json:"kubeConfigPath"`
+	} `json:"file"`
+	TLSBootstrapToken string `json:"tlsBootstrapToken"`
+	Timeout           string `json:"timeout"`
+}
+
+func (d discovery) check(c *checker, path string) {
+	switch {
+	case d.BootstrapToken != nil && d.File != nil:
+		c.errorf(path, "bootstrapToken and file discovery can't both be set")
+	case d.BootstrapToken == nil && d.File == nil:
+		c.errorf(path, "bootstrapToken or file discovery is required")
+	}
+
+	if b := d.BootstrapToken; b != nil {
+		p := join(path, "bootstrapToken")
+		c.token(join(p, "token"), b.Token)
+		if b.APIServerEndpoint != "" {
+			c.hostPort(join(p, "apiServerEndpoint"), b.APIServerEndpoint, true)
+		}
+		c.caCertHashes(join(p, "caCertHashes"), b.CACertHashes)
+		if len(b.CACertHashes) == 0 && !b.UnsafeSkipCAVerification {
+			c.errorf(join(p, "caCertHashes"), "required unless unsafeSkipCAVerification is set")
+		}
+	}
+
+	if d.TLSBootstrapToken != "" {
+		c.token(join(path, "tlsBootstrapToken"), d.TLSBootstrapToken)
+	}
+	if d.Timeout != "" {
+		c.duration(join(path, "timeout"), d.Timeout)
+	}
+}
+
+type patches struct {
+	Directory string `json:"directory"`
+}
+
+type initConfigurationV1beta1 struct {
+	typeMeta
+	BootstrapTokens  []bootstrapToken        `json:"bootstrapTokens"`
+	NodeRegistration nodeRegistrationV1beta1 `json:"nodeRegistration"`
+	LocalAPIEndpoint apiEndpoint             `json:"localAPIEndpoint"`
+}
+
+func (i *initConfigurationV1beta1) check(c *checker) {
+	checkInit(c, i.BootstrapTokens, i.LocalAPIEndpoint)
+	i.NodeRegistration.check(c, "nodeRegistration")
+}
+
+type initConfigurationV1beta2 struct {
+	typeMeta
+	BootstrapTokens  []bootstrapToken        `json:"bootstrapTokens"`
+	NodeRegistration nodeRegistrationV1beta2 `json:"nodeRegistration"`
+	LocalAPIEndpoint apiEndpoint             `json:"localAPIEndpoint"`
+	CertificateKey   string                  `json:"certificateKey"`
+}
+
+func (i *initConfigurationV1beta2) check(c *checker) {
+	checkInit(c, i.BootstrapTokens, i.LocalAPIEndpoint)
+	i.NodeRegistration.check(c, "nodeRegistration")
+}
+
+type initConfigurationV1beta3 struct {
+	typeMeta
+	BootstrapTokens  []bootstrapToken        `json:"bootstrapTokens"`
+	NodeRegistration nodeRegistrationV1beta3 `json:"nodeRegistration"`
+	LocalAPIEndpoint apiEndpoint             `json:"localAPIEndpoint"`
+	CertificateKey   string                  `json:"certificateKey"`
+	SkipPhases       []string                `json:"skipPhases"`
+	Patches          *patches                `json:"patches"`
+}
+
+func (i *initConfigurationV1beta3) check(c *checker) {
+	checkInit(c, i.BootstrapTokens, i.LocalAPIEndpoint)
+	i.NodeRegistration.check(c, "nodeRegistration")
+}
+
+// checkInit checks the InitConfiguration fields every beta version has
+func checkInit(c *checker, tokens []bootstrapToken, endpoint apiEndpoint) {
+	for i, token := range tokens {
+		token.check(c, index("bootstrapTokens", i))
+	}
+	endpoint.check(c, "localAPIEndpoint")
+}
+
+type joinConfigurationV1beta1 struct {
+	typeMeta
+	NodeRegistration nodeRegistrationV1beta1 `json:"nodeRegistration"`
+	CACertPath       string                  `json:"caCertPath"`
+	Discovery        discovery               `json:"discovery"`
+	ControlPlane     *struct {
+		LocalAPIEndpoint apiEndpoint `json:"localAPIEndpoint"`
+	} `json:"controlPlane"`
+}
+
+func (j *joinConfigurationV1beta1) check(c *checker) {
+	j.NodeRegistration.check(c, "nodeRegistration")
+	j.Discovery.check(c, "discovery")
+	if j.ControlPlane != nil {
+		j.ControlPlane.LocalAPIEndpoint.check(c, "controlPlane.localAPIEndpoint")
+	}
+}
+
+type joinControlPlaneV1beta2 struct {
+	LocalAPIEndpoint apiEndpoint `json:"localAPIEndpoint"`
+	CertificateKey   string      `json:"certificateKey"`
+}
+
+type joinConfigurationV1beta2 struct {
+	typeMeta
+	NodeRegistration nodeRegistrationV1beta2  `json:"nodeRegistration"`
+	CACertPath       string                   `json:"caCertPath"`
+	Discovery        discovery                `json:"discovery"`
+	ControlPlane     *joinControlPlaneV1beta2 `json:"controlPlane"`
+}
+
+func (j *joinConfigurationV1beta2) check(c *checker) {
+	j.NodeRegistration.check(c, "nodeRegistration")
+	j.Discovery.check(c, "discovery")
+	if j.ControlPlane != nil {
+		j.ControlPlane.LocalAPIEndpoint.check(c, "controlPlane.localAPIEndpoint")
+	}
+}
+
+type joinConfigurationV1beta3 struct {
+	typeMeta
+	NodeRegistration nodeRegistrationV1beta3  `json:"nodeRegistration"`
+	CACertPath       string                   `json:"caCertPath"`
+	Discovery        discovery                `json:"discovery"`
+	ControlPlane     *joinControlPlaneV1beta2 `json:"controlPlane"`
+	SkipPhases       []string                 `json:"skipPhases"`
+	Patches          *patches                 `json:"patches"`
+}
+
+func (j *joinConfigurationV1beta3) check(c *checker) {
+	j.NodeRegistration.check(c, "nodeRegistration")
+	j.Discovery.check(c, "discovery")
+	if j.ControlPlane != nil {
+		j.ControlPlane.LocalAPIEndpoint.check(c, "controlPlane.localAPIEndpoint")
+	}
+}
@@ -0,0 +1,302 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Error is a problem with one field of a config document
+type Error struct {
+	// Path is the field, starting with the document kind, e.g.
+	// ClusterConfiguration.apiServer.certSANs[3]
+	Path    string
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Errors are all the problems found in a config
+type Errors []Error
+
+func (e Errors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// document is a typed kubeadm config document that checks its own values
+type document interface {
+	check(c *checker)
+}
+
+// kinds maps an apiVersion and kind to a new typed document
+var kinds = map[string]func() document{}
+
+// register adds the document types of a kubeadm API version
+func register(apiVersion string, docs map[string]func() document) {
+	for kind, doc := range docs {
+		kinds[apiVersion+"/"+kind] = doc
+	}
+}
+
+// Config validates a kubeadm config of one or more JSON or YAML documents.
+// Every document of a known kubeadm API version is checked against its type,
+// rejecting unknown fields, and then for values kubeadm would fail on. All
+// problems are returned together as Errors
+func Config(data []byte) error {
+	values, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("no documents found")
+	}
+
+	c := &checker{}
+	for i, value := range values {
+		c.document(i, value)
+	}
+
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+	return nil
+}
+
+// Parse splits a config into documents. JSON is a subset of YAML, so both are
+// read as a YAML stream, and objects are returned as map[string]interface{}
+func Parse(data []byte) ([]interface{}, error) {
+	var values []interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var value interface{}
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing config: %v", err)
+		}
+		if value == nil {
+			continue
+		}
+		values = append(values, normalize(value))
+	}
+	return values, nil
+}
+
+// normalize converts YAML maps to the map[string]interface{} JSON uses
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// checker collects the problems found in a config
+type checker struct {
+	// root is the start of every path in the current document
+	root string
+	errs Errors
+}
+
+// errorf records a problem with a field of the current document
+func (c *checker) errorf(path, format string, args ...interface{}) {
+	c.errs = append(c.errs, Error{Path: join(c.root, path), Message: fmt.Sprintf(format, args...)})
+}
+
+// join appends a field name to a path
+func join(path, name string) string {
+	if path == "" || name == "" {
+		return path + name
+	}
+	return path + "." + name
+}
+
+// index appends a list index to a path
+func index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// document checks one document against the type of its apiVersion and kind
+func (c *checker) document(i int, value interface{}) {
+	c.root = fmt.Sprintf("document %d", i+1)
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		c.errorf("", "must be an object")
+		return
+	}
+
+	apiVersion, _ := fields["apiVersion"].(string)
+	kind, _ := fields["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		c.errorf("", "apiVersion and kind must be set")
+		return
+	}
+	c.root = kind
+
+	newDoc, ok := kinds[apiVersion+"/"+kind]
+	if !ok {
+		// only kubeadm's own documents are known
+		if strings.HasPrefix(apiVersion, "kubeadm.k8s.io/") {
+			c.errorf("", "unknown kind %s for %s", kind, apiVersion)
+		}
+		return
+	}
+
+	// fields of the wrong type have been reported and removed, so the rest of
+	// the document can still be decoded and checked
+	doc := newDoc()
+	c.fields("", value, reflect.TypeOf(doc))
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(data, doc)
+	}
+	if err != nil {
+		c.errorf("", "%v", err)
+		return
+	}
+
+	doc.check(c)
+}
+
+// fields checks a value has the structure of a type, using the JSON names of
+// struct fields. It returns false if the value is of the wrong type, and
+// removes fields of the wrong type from objects, so a list with an item of the
+// wrong type is removed whole
+func (c *checker) fields(path string, value interface{}, t reflect.Type) bool {
+	if value == nil {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return c.fields(path, value, t.Elem())
+
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			c.errorf(path, "must be an object")
+			return false
+		}
+		known := jsonFields(t)
+		for _, key := range sortedKeys(m) {
+			field, ok := known[key]
+			if !ok {
+				c.errorf(join(path, key), "unknown field")
+				continue
+			}
+			if !c.fields(join(path, key), m[key], field) {
+				delete(m, key)
+			}
+		}
+
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			c.errorf(path, "must be an object")
+			return false
+		}
+		for _, key := range sortedKeys(m) {
+			if !c.fields(join(path, key), m[key], t.Elem()) {
+				delete(m, key)
+			}
+		}
+
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			c.errorf(path, "must be a list")
+			return false
+		}
+		valid := true
+		for i, item := range items {
+			if !c.fields(index(path, i), item, t.Elem()) {
+				valid = false
+			}
+		}
+		return valid
+
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			c.errorf(path, "must be a string, got %v", value)
+			return false
+		}
+
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			c.errorf(path, "must be true or false, got %v", value)
+			return false
+		}
+
+	case reflect.Int:
+		switch n := value.(type) {
+		case int, int64, uint64:
+		case float64:
+			if n != float64(int64(n)) {
+				c.errorf(path, "must be an integer, got %v", value)
+				return false
+			}
+		default:
+			c.errorf(path, "must be an integer, got %v", value)
+			return false
+		}
+	}
+	return true
+}
+
+// jsonFields returns the types of a struct's fields by JSON name, including
+// the fields of embedded structs unless the struct overrides them
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		for name, ft := range jsonFields(field.Type) {
+			fields[name] = ft
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous || name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,567 @@
+package validate
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// betaVersions are the kubeadm API versions that share the beta documents
+var betaVersions = []string{"kubeadm.k8s.io/v1beta1", "kubeadm.k8s.io/v1beta2", "kubeadm.k8s.io/v1beta3"}
+
+// validate returns the problems Config finds in a config, sorted
+func validate(t *testing.T, config string) []string {
+	err := Config([]byte(config))
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+	var problems []string
+	for _, e := range errs {
+		problems = append(problems, e.Error())
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func checkProblems(t *testing.T, name, config string, want []string) {
+	got := validate(t, config)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: got problems\n%s\nwant\n%s", name, strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParse(t *testing.T) {
+	config := `
+---
+kind: ClusterConfiguration
+apiServer:
+  extraArgs:
+    profiling: "false"
+  certSANs: [{1: one}]
+---
+{"kind": "InitConfiguration", "bootstrapTokens": [{"ttl": "0"}]}
+---
+`
+	values, err := Parse([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		map[string]interface{}{
+			"kind": "ClusterConfiguration",
+			"apiServer": map[string]interface{}{
+				"extraArgs": map[string]interface{}{"profiling": "false"},
+				"certSANs":  []interface{}{map[string]interface{}{"1": "one"}},
+			},
+		},
+		map[string]interface{}{
+			"kind":            "InitConfiguration",
+			"bootstrapTokens": []interface{}{map[string]interface{}{"ttl": "0"}},
+		},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got %#v, want %#v", values, want)
+	}
+
+	if _, err := Parse([]byte("kind: [ClusterConfiguration")); err == nil || !strings.HasPrefix(err.Error(), "error parsing config:") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := Config([]byte("---\n")); err == nil || err.Error() != "no documents found" {
+		t.Errorf("unexpected error %v for an empty config", err)
+	}
+}
+
+func TestConfigDocuments(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		want   []string
+	}{
+		"not an object": {
+			"- kind: ClusterConfiguration",
+			[]string{"document 1: must be an object"},
+		},
+		"no kind": {
+			"apiVersion: kubeadm.k8s.io/v1beta2\n---\nkind: ClusterConfiguration",
+			[]string{"document 1: apiVersion and kind must be set", "document 2: apiVersion and kind must be set"},
+		},
+		"unknown kubeadm kind": {
+			"apiVersion: kubeadm.k8s.io/v1beta2\nkind: MasterConfiguration",
+			[]string{"MasterConfiguration: unknown kind MasterConfiguration for kubeadm.k8s.io/v1beta2"},
+		},
+		"other API groups": {
+			"apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\nanything: goes",
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		checkProblems(t, name, test.config, test.want)
+	}
+}
+
+func TestConfigV1alpha1(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		want   []string
+	}{
+		"valid": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+kubernetesVersion: v1.10.13
+api:
+  advertiseAddress: 10.0.0.1
+  bindPort: 6443
+  controlPlaneEndpoint: dc1-k1.example.com
+token: abcdef.0123456789abcdef
+tokenTTL: "0"
+tokenUsages: [signing, authentication]
+tokenGroups: ["system:bootstrappers:kubeadm:default-node-token"]
+apiServerCertSANs: [10.0.0.1, "fd00::1", dc1-k1.example.com, "*.example.com"]
+apiServerExtraArgs:
+  apiserver-count: "3"
+networking:
+  podSubnet: 10.244.0.0/16
+  serviceSubnet: 10.96.0.0/12
+etcd:
+  extraArgs:
+    listen-client-urls: https://0.0.0.0:2379
+kubeProxy:
+  config:
+    clusterCIDR: 10.244.0.0/16
+---
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: NodeConfiguration
+token: abcdef.0123456789abcdef
+discoveryTokenAPIServers: ["dc1-k1.example.com:6443"]
+discoveryTokenCACertHashes: ["sha256:cb5079d5e210c425a4305d1a67d749409c182f02932066c0e869d85c4286ccb1"]
+`,
+			nil,
+		},
+		"unknown fields": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+apiServerCertSAN: [dc1-k1.example.com]
+api:
+  advertiseAddres: 10.0.0.1
+etcd:
+  local: {}
+`,
+			[]string{
+				"MasterConfiguration.apiServerCertSAN: unknown field",
+				"MasterConfiguration.api.advertiseAddres: unknown field",
+				"MasterConfiguration.etcd.local: unknown field",
+			},
+		},
+		"type mismatches": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+api:
+  bindPort: "6443"
+  advertiseAddress: 10.0.0.300
+noTaintMaster: "yes"
+tokenUsages: signing
+apiServerExtraArgs:
+  profiling: false
+featureGates:
+  CoreDNS: 1
+networking: 10.244.0.0/16
+`,
+			[]string{
+				"MasterConfiguration.api.bindPort: must be an integer, got 6443",
+				"MasterConfiguration.noTaintMaster: must be true or false, got yes",
+				"MasterConfiguration.tokenUsages: must be a list",
+				"MasterConfiguration.apiServerExtraArgs.profiling: must be a string, got false",
+				"MasterConfiguration.featureGates.CoreDNS: must be true or false, got 1",
+				"MasterConfiguration.networking: must be an object",
+				// the rest of the document is still checked
+				`MasterConfiguration.api.advertiseAddress: invalid IP address "10.0.0.300"`,
+			},
+		},
+		"tokens": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+token: ABCDEF.0123456789abcdef
+tokenTTL: 1 day
+tokenUsages: [signing, encryption]
+tokenGroups: ["system:bootstrappers:ok", admins]
+`,
+			[]string{
+				`MasterConfiguration.token: invalid bootstrap token "ABCDEF.0123456789abcdef", must match [a-z0-9]{6}.[a-z0-9]{16}`,
+				`MasterConfiguration.tokenTTL: invalid duration "1 day"`,
+				`MasterConfiguration.tokenUsages[1]: invalid token usage "encryption", must be one of: signing, authentication`,
+				`MasterConfiguration.tokenGroups[1]: invalid token group "admins", must start with system:bootstrappers:`,
+			},
+		},
+		"addresses": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+api:
+  advertiseAddress: dc1-k1.example.com
+  bindPort: 70000
+  controlPlaneEndpoint: ":6443"
+apiServerCertSANs: ["", 10.0.0.256, Dc1-K1.example.com, "fd00::zz"]
+networking:
+  podSubnet: 10.244.0.0/16,10.245.0.0
+  serviceSubnet: 10.96.0.0
+`,
+			[]string{
+				`MasterConfiguration.api.advertiseAddress: invalid IP address "dc1-k1.example.com"`,
+				"MasterConfiguration.api.bindPort: invalid port 70000",
+				`MasterConfiguration.api.controlPlaneEndpoint: no host in ":6443"`,
+				"MasterConfiguration.apiServerCertSANs[0]: must not be empty",
+				`MasterConfiguration.apiServerCertSANs[1]: invalid IP address "10.0.0.256"`,
+				`MasterConfiguration.apiServerCertSANs[2]: invalid DNS name "Dc1-K1.example.com", must be lowercase letters, digits, '-' and '.'`,
+				`MasterConfiguration.apiServerCertSANs[3]: invalid IP address "fd00::zz"`,
+				`MasterConfiguration.networking.podSubnet: invalid CIDR "10.245.0.0"`,
+				`MasterConfiguration.networking.serviceSubnet: invalid CIDR "10.96.0.0"`,
+			},
+		},
+		"extra args": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+apiServerExtraArgs:
+  profiling: ""
+  apiserver-count: "0"
+  audit-log-maxage: "0"
+controllerManagerExtraArgs:
+  terminated-pod-gc-threshold: many
+schedulerExtraArgs:
+  audit-log-maxsize: "-1"
+`,
+			[]string{
+				"MasterConfiguration.apiServerExtraArgs.profiling: must not be empty",
+				`MasterConfiguration.apiServerExtraArgs.apiserver-count: must be an integer of at least 1, got "0"`,
+				`MasterConfiguration.controllerManagerExtraArgs.terminated-pod-gc-threshold: must be an integer of at least 0, got "many"`,
+				`MasterConfiguration.schedulerExtraArgs.audit-log-maxsize: must be an integer of at least 0, got "-1"`,
+			},
+		},
+		"external etcd": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+etcd:
+  endpoints: [https://10.0.0.1:2379, http://10.0.0.2:2379, 10.0.0.3]
+  caFile: /etc/etcd/ca.crt
+  certFile: /etc/etcd/client.crt
+  # ignored for external etcd
+  extraArgs:
+    data-dir: ""
+`,
+			[]string{
+				`MasterConfiguration.etcd.endpoints[1]: must use https when etcd TLS files are set, got "http://10.0.0.2:2379"`,
+				`MasterConfiguration.etcd.endpoints[2]: invalid URL "10.0.0.3"`,
+				"MasterConfiguration.etcd: certFile and keyFile must be set together",
+			},
+		},
+		"local etcd": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: MasterConfiguration
+etcd:
+  extraArgs:
+    data-dir: ""
+  serverCertSANs: [ETCD]
+`,
+			[]string{
+				"MasterConfiguration.etcd.extraArgs.data-dir: must not be empty",
+				`MasterConfiguration.etcd.serverCertSANs[0]: invalid DNS name "ETCD", must be lowercase letters, digits, '-' and '.'`,
+			},
+		},
+		"node": {
+			`
+apiVersion: kubeadm.k8s.io/v1alpha1
+kind: NodeConfiguration
+discoveryToken: abcdef.0123456789abcdef
+discoveryTokenAPIServers: [dc1-k1.example.com, "dc1-k1.example.com:0"]
+discoveryTokenCACertHashes: [cb5079d5e210c425a4305d1a67d749409c182f02932066c0e869d85c4286ccb1]
+discoveryTimeout: 5
+`,
+			[]string{
+				"NodeConfiguration.discoveryTimeout: must be a string, got 5",
+				"NodeConfiguration.token: a token or a discovery file is required",
+				`NodeConfiguration.discoveryTokenAPIServers[0]: invalid address "dc1-k1.example.com", must be host:port`,
+				`NodeConfiguration.discoveryTokenAPIServers[1]: invalid port in "dc1-k1.example.com:0"`,
+				`NodeConfiguration.discoveryTokenCACertHashes[0]: invalid CA cert hash "cb5079d5e210c425a4305d1a67d749409c182f02932066c0e869d85c4286ccb1", must be sha256:<hex>`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		checkProblems(t, name, test.config, test.want)
+	}
+}
+
+func TestConfigBeta(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		want   []string
+	}{
+		"valid": {
+			`
+apiVersion: $apiVersion
+kind: InitConfiguration
+bootstrapTokens:
+- token: abcdef.0123456789abcdef
+  ttl: 24h0m0s
+  usages: [signing, authentication]
+  groups: ["system:bootstrappers:kubeadm:default-node-token"]
+localAPIEndpoint:
+  advertiseAddress: "fd00::1"
+  bindPort: 6443
+nodeRegistration:
+  kubeletExtraArgs:
+    cloud-provider: aws
+---
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+kubernetesVersion: v1.18.20
+controlPlaneEndpoint: dc1-k1.example.com:6443
+apiServer:
+  certSANs: [10.0.0.1, dc1-k1.example.com]
+  extraArgs:
+    apiserver-count: "3"
+  timeoutForControlPlane: 4m0s
+etcd:
+  external:
+    endpoints: [https://10.0.0.1:2379]
+    caFile: /etc/etcd/ca.crt
+    certFile: /etc/etcd/client.crt
+    keyFile: /etc/etcd/client.key
+networking:
+  podSubnet: 10.244.0.0/16,fd00:10:244::/56
+---
+apiVersion: $apiVersion
+kind: JoinConfiguration
+discovery:
+  bootstrapToken:
+    token: abcdef.0123456789abcdef
+    apiServerEndpoint: "[fd00::1]:6443"
+    unsafeSkipCAVerification: true
+controlPlane:
+  localAPIEndpoint:
+    advertiseAddress: 10.0.0.2
+`,
+			nil,
+		},
+		"unknown fields": {
+			`
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+apiServer:
+  certSAN: [dc1-k1.example.com]
+  extraVolumes:
+  - name: audit
+    hostPath: /var/log/audit
+    readonly: true
+etcd:
+  local:
+    endpoints: [https://10.0.0.1:2379]
+`,
+			[]string{
+				"ClusterConfiguration.apiServer.certSAN: unknown field",
+				"ClusterConfiguration.apiServer.extraVolumes[0].readonly: unknown field",
+				"ClusterConfiguration.etcd.local.endpoints: unknown field",
+			},
+		},
+		"type mismatches": {
+			`
+apiVersion: $apiVersion
+kind: JoinConfiguration
+nodeRegistration:
+  taints: NoSchedule
+  kubeletExtraArgs: [node-ip=10.0.0.2]
+discovery:
+  bootstrapToken:
+    token: abcdef.0123456789abcdef
+    apiServerEndpoint: 10.0.0.1:6443
+    unsafeSkipCAVerification: "true"
+controlPlane:
+  localAPIEndpoint:
+    bindPort: 6443.5
+`,
+			[]string{
+				"JoinConfiguration.nodeRegistration.taints: must be a list",
+				"JoinConfiguration.nodeRegistration.kubeletExtraArgs: must be an object",
+				"JoinConfiguration.discovery.bootstrapToken.unsafeSkipCAVerification: must be true or false, got true",
+				"JoinConfiguration.controlPlane.localAPIEndpoint.bindPort: must be an integer, got 6443.5",
+				// unsafeSkipCAVerification wasn't decoded
+				"JoinConfiguration.discovery.bootstrapToken.caCertHashes: required unless unsafeSkipCAVerification is set",
+			},
+		},
+		"tokens": {
+			`
+apiVersion: $apiVersion
+kind: InitConfiguration
+bootstrapTokens:
+- token: abcdef.0123456789abcdef
+- token: abcdef
+  ttl: forever
+  usages: [authentication, signing, encryption]
+  groups: [system:masters]
+`,
+			[]string{
+				`InitConfiguration.bootstrapTokens[1].token: invalid bootstrap token "abcdef", must match [a-z0-9]{6}.[a-z0-9]{16}`,
+				`InitConfiguration.bootstrapTokens[1].ttl: invalid duration "forever"`,
+				`InitConfiguration.bootstrapTokens[1].usages[2]: invalid token usage "encryption", must be one of: signing, authentication`,
+				`InitConfiguration.bootstrapTokens[1].groups[0]: invalid token group "system:masters", must start with system:bootstrappers:`,
+			},
+		},
+		"addresses": {
+			`
+apiVersion: $apiVersion
+kind: InitConfiguration
+localAPIEndpoint:
+  advertiseAddress: 10.0.0
+  bindPort: -1
+---
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+controlPlaneEndpoint: dc1-k1.example.com:https
+apiServer:
+  certSANs: ["*.example.com", 10.0.0.1.1, dc1_k1.example.com]
+networking:
+  podSubnet: 10.244.0.0/16,fd00:10:244::/129
+  serviceSubnet: ""
+`,
+			[]string{
+				`InitConfiguration.localAPIEndpoint.advertiseAddress: invalid IP address "10.0.0"`,
+				"InitConfiguration.localAPIEndpoint.bindPort: invalid port -1",
+				`ClusterConfiguration.controlPlaneEndpoint: invalid port in "dc1-k1.example.com:https"`,
+				`ClusterConfiguration.apiServer.certSANs[1]: invalid IP address "10.0.0.1.1"`,
+				`ClusterConfiguration.apiServer.certSANs[2]: invalid DNS name "dc1_k1.example.com", must be lowercase letters, digits, '-' and '.'`,
+				`ClusterConfiguration.networking.podSubnet: invalid CIDR "fd00:10:244::/129"`,
+			},
+		},
+		"extra args": {
+			`
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+apiServer:
+  extraArgs:
+    audit-log-maxbackup: "1.5"
+    apiserver-count: "1"
+controllerManager:
+  extraArgs:
+    cluster-signing-cert-file: ""
+etcd:
+  local:
+    extraArgs:
+      snapshot-count: ""
+---
+apiVersion: $apiVersion
+kind: InitConfiguration
+nodeRegistration:
+  kubeletExtraArgs:
+    node-ip: ""
+`,
+			[]string{
+				`ClusterConfiguration.apiServer.extraArgs.audit-log-maxbackup: must be an integer of at least 0, got "1.5"`,
+				"ClusterConfiguration.controllerManager.extraArgs.cluster-signing-cert-file: must not be empty",
+				"ClusterConfiguration.etcd.local.extraArgs.snapshot-count: must not be empty",
+				"InitConfiguration.nodeRegistration.kubeletExtraArgs.node-ip: must not be empty",
+			},
+		},
+		"external etcd": {
+			`
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+etcd:
+  external:
+    endpoints: [http://10.0.0.1:2379, "https://"]
+    keyFile: /etc/etcd/client.key
+---
+apiVersion: $apiVersion
+kind: ClusterConfiguration
+etcd:
+  local: {}
+  external: {}
+`,
+			[]string{
+				`ClusterConfiguration.etcd.external.endpoints[0]: must use https when etcd TLS files are set, got "http://10.0.0.1:2379"`,
+				`ClusterConfiguration.etcd.external.endpoints[1]: invalid URL "https://"`,
+				"ClusterConfiguration.etcd.external: certFile and keyFile must be set together",
+				"ClusterConfiguration.etcd: local and external etcd can't both be set",
+				"ClusterConfiguration.etcd.external.endpoints: at least one endpoint is required",
+			},
+		},
+		"discovery": {
+			`
+apiVersion: $apiVersion
+kind: JoinConfiguration
+discovery:
+  timeout: 5 minutes
+---
+apiVersion: $apiVersion
+kind: JoinConfiguration
+discovery:
+  bootstrapToken:
+    token: abcdef.0123456789abcdef
+    apiServerEndpoint: dc1-k1.example.com
+    caCertHashes: ["sha256:CB5079"]
+  file:
+    kubeConfigPath: /etc/kubernetes/discovery.conf
+  tlsBootstrapToken: abcdef.0123456789ABCDEF
+`,
+			[]string{
+				"JoinConfiguration.discovery: bootstrapToken or file discovery is required",
+				`JoinConfiguration.discovery.timeout: invalid duration "5 minutes"`,
+				"JoinConfiguration.discovery: bootstrapToken and file discovery can't both be set",
+				`JoinConfiguration.discovery.bootstrapToken.apiServerEndpoint: invalid address "dc1-k1.example.com", must be host:port`,
+				`JoinConfiguration.discovery.bootstrapToken.caCertHashes[0]: invalid CA cert hash "sha256:CB5079", must be sha256:<hex>`,
+				`JoinConfiguration.discovery.tlsBootstrapToken: invalid bootstrap token "abcdef.0123456789ABCDEF", must match [a-z0-9]{6}.[a-z0-9]{16}`,
+			},
+		},
+	}
+
+	for _, apiVersion := range betaVersions {
+		for name, test := range tests {
+			checkProblems(t, apiVersion+" "+name, strings.Replace(test.config, "$apiVersion", apiVersion, -1), test.want)
+		}
+	}
+}
+
+func TestConfigBetaVersions(t *testing.T) {
+	// fields that only some beta versions have, and the versions without them
+	tests := []struct {
+		kind    string
+		fields  string
+		field   string
+		without []string
+	}{
+		{"ClusterConfiguration", "dns:\n  type: CoreDNS", "dns.type", []string{"kubeadm.k8s.io/v1beta3"}},
+		{"ClusterConfiguration", "useHyperKubeImage: true", "useHyperKubeImage", []string{"kubeadm.k8s.io/v1beta3"}},
+		{"InitConfiguration", "certificateKey: abc", "certificateKey", []string{"kubeadm.k8s.io/v1beta1"}},
+		{"InitConfiguration", "nodeRegistration:\n  ignorePreflightErrors: [all]", "nodeRegistration.ignorePreflightErrors", []string{"kubeadm.k8s.io/v1beta1"}},
+		{"InitConfiguration", "skipPhases: [addon/kube-proxy]", "skipPhases", []string{"kubeadm.k8s.io/v1beta1", "kubeadm.k8s.io/v1beta2"}},
+		{"InitConfiguration", "nodeRegistration:\n  imagePullPolicy: Never", "nodeRegistration.imagePullPolicy", []string{"kubeadm.k8s.io/v1beta1", "kubeadm.k8s.io/v1beta2"}},
+		{"JoinConfiguration", "patches:\n  directory: /etc/kubeadm/patches", "patches", []string{"kubeadm.k8s.io/v1beta1", "kubeadm.k8s.io/v1beta2"}},
+	}
+
+	for _, test := range tests {
+		for _, apiVersion := range betaVersions {
+			config := "apiVersion: " + apiVersion + "\nkind: " + test.kind + "\n" + test.fields
+			if test.kind == "JoinConfiguration" {
+				config += "\ndiscovery:\n  file:\n    kubeConfigPath: discovery.conf"
+			}
+			var want []string
+			for _, v := range test.without {
+				if v == apiVersion {
+					want = []string{test.kind + "." + test.field + ": unknown field"}
+				}
+			}
+			checkProblems(t, apiVersion+" "+test.kind+"."+test.field, config, want)
+		}
+	}
+}